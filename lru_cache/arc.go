@@ -0,0 +1,279 @@
+package cache
+
+import "sync"
+
+var _ Cache[Key, interface{}] = (*ARCCache[Key, interface{}])(nil)
+
+// ARCCache is an implementation of a cache with the adaptive replacement policy.
+// It splits entries between recently used once and frequently used lists and
+// remembers keys recently evicted from each of them in ghost lists. Hits in ghost lists
+// move the target balance between the two, adapting the cache to the workload.
+// It implements Cache interface and is safe for concurrent use.
+// Capacity Unlimited means number of entries is not limited, cache of zero capacity stores nothing.
+type ARCCache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	// p is target size of recent list
+	p        int
+	recent   *lruList[K, V]
+	frequent *lruList[K, V]
+	// ghosts of entries evicted from recent and frequent lists
+	recentGhost   *lruList[K, struct{}]
+	frequentGhost *lruList[K, struct{}]
+	counters      counters
+}
+
+// NewARC returns pointer to newly created ARCCache with given capacity.
+func NewARC[K comparable, V any](capacity int) *ARCCache[K, V] {
+	return &ARCCache[K, V]{
+		capacity:      max(capacity, 0),
+		recent:        newLRUList[K, V](),
+		frequent:      newLRUList[K, V](),
+		recentGhost:   newLRUList[K, struct{}](),
+		frequentGhost: newLRUList[K, struct{}](),
+	}
+}
+
+// Set stores key with given value.
+// Sets return boolean indicating existence of a given key in the cache.
+func (c *ARCCache[K, V]) Set(key K, value V) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counters.sets.Add(1)
+
+	// cache of zero capacity keeps nothing
+	if c.capacity == 0 {
+		c.counters.evictions.Add(1)
+		return false, nil
+	}
+
+	// second use promotes entry to frequent list
+	if _, ok := c.recent.remove(key); ok {
+		c.frequent.add(key, value)
+		return true, nil
+	}
+
+	if e, ok := c.frequent.get(key); ok {
+		e.value = value
+		return true, nil
+	}
+
+	if c.recentGhost.contains(key) {
+		// recent list was too small, grow its target
+		delta := 1
+		if c.frequentGhost.len() > c.recentGhost.len() {
+			delta = c.frequentGhost.len() / c.recentGhost.len()
+		}
+		c.p = min(c.p+delta, c.capacity)
+
+		c.ensureSpace(false)
+		c.recentGhost.remove(key)
+		c.frequent.add(key, value)
+
+		return false, nil
+	}
+
+	if c.frequentGhost.contains(key) {
+		// frequent list was too small, shrink target of recent list
+		delta := 1
+		if c.recentGhost.len() > c.frequentGhost.len() {
+			delta = c.recentGhost.len() / c.frequentGhost.len()
+		}
+		c.p = max(c.p-delta, 0)
+
+		c.ensureSpace(true)
+		c.frequentGhost.remove(key)
+		c.frequent.add(key, value)
+
+		return false, nil
+	}
+
+	c.ensureSpace(false)
+
+	if c.recentGhost.len() > c.capacity-c.p {
+		c.recentGhost.removeOldest()
+	}
+
+	if c.frequentGhost.len() > c.p {
+		c.frequentGhost.removeOldest()
+	}
+
+	c.recent.add(key, value)
+
+	return false, nil
+}
+
+// Get returns value associated with a given key in the cache and
+// boolean indicating existence of key in the cache.
+func (c *ARCCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.recent.remove(key); ok {
+		c.counters.hits.Add(1)
+		c.frequent.add(key, e.value)
+
+		return e.value, true
+	}
+
+	if e, ok := c.frequent.get(key); ok {
+		c.counters.hits.Add(1)
+		return e.value, true
+	}
+
+	c.counters.misses.Add(1)
+	var zero V
+
+	return zero, false
+}
+
+// Peek returns value associated with a given key in the cache and
+// boolean indicating existence of key in the cache without counting it as use.
+func (c *ARCCache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.recent.peek(key); ok {
+		return e.value, true
+	}
+
+	if e, ok := c.frequent.peek(key); ok {
+		return e.value, true
+	}
+
+	var zero V
+
+	return zero, false
+}
+
+// Contains reports whether given key exists in the cache without counting it as use.
+func (c *ARCCache[K, V]) Contains(key K) bool {
+	_, ok := c.Peek(key)
+
+	return ok
+}
+
+// Delete removes given key from the cache.
+// Delete returns boolean indicating existence of a given key in the cache.
+func (c *ARCCache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.recentGhost.remove(key)
+	c.frequentGhost.remove(key)
+
+	if _, ok := c.recent.remove(key); ok {
+		return true
+	}
+
+	_, ok := c.frequent.remove(key)
+
+	return ok
+}
+
+// Len returns number of entries in the cache.
+func (c *ARCCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.recent.len() + c.frequent.len()
+}
+
+// Cap returns capacity of the cache.
+func (c *ARCCache[K, V]) Cap() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.capacity
+}
+
+// Keys returns keys of frequently used entries followed by keys of entries used once.
+// Keys of each group are ordered from the most to the least recently used.
+func (c *ARCCache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append(c.frequent.keys(), c.recent.keys()...)
+}
+
+// Resize changes capacity of the cache, evicting entries which do not fit into new capacity.
+// Resize returns number of evicted entries.
+func (c *ARCCache[K, V]) Resize(capacity int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capacity = max(capacity, 0)
+	c.p = min(c.p, c.capacity)
+
+	evicted := 0
+	for c.recent.len()+c.frequent.len() > c.capacity {
+		c.replace(false)
+		evicted++
+	}
+
+	for c.recentGhost.len() > c.capacity {
+		c.recentGhost.removeOldest()
+	}
+
+	for c.frequentGhost.len() > c.capacity {
+		c.frequentGhost.removeOldest()
+	}
+
+	return evicted
+}
+
+// Clear removes all data in the cache.
+func (c *ARCCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.recent.clear()
+	c.frequent.clear()
+	c.recentGhost.clear()
+	c.frequentGhost.clear()
+	c.p = 0
+}
+
+// Stats returns snapshot of the cache state.
+func (c *ARCCache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{Len: c.recent.len() + c.frequent.len()}
+	c.counters.fill(&stats)
+
+	return stats
+}
+
+// ensureSpace evicts an entry if the cache is full.
+// frequentGhostHit tells that new entry comes from the frequent ghost list.
+func (c *ARCCache[K, V]) ensureSpace(frequentGhostHit bool) {
+	if n := c.recent.len() + c.frequent.len(); n < c.capacity {
+		return
+	}
+
+	c.replace(frequentGhostHit)
+}
+
+// replace evicts single entry either from recent or from frequent list
+// depending on target size of recent list and remembers its key in corresponding ghost list.
+func (c *ARCCache[K, V]) replace(frequentGhostHit bool) {
+	c.counters.evictions.Add(1)
+
+	recentLen := c.recent.len()
+	if recentLen > 0 && (recentLen > c.p || (recentLen == c.p && frequentGhostHit)) {
+		e, _ := c.recent.removeOldest()
+		c.recentGhost.add(e.key, struct{}{})
+
+		return
+	}
+
+	if e, ok := c.frequent.removeOldest(); ok {
+		c.frequentGhost.add(e.key, struct{}{})
+		return
+	}
+
+	e, _ := c.recent.removeOldest()
+	c.recentGhost.add(e.key, struct{}{})
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestARCCachePromotesOnSecondUse(t *testing.T) {
+	c := NewARC[Key, string](4)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+
+	assert.True(t, c.recent.contains("testKey1"))
+
+	c.Get("testKey1")
+
+	assert.True(t, c.frequent.contains("testKey1"))
+	assert.Equal(t, c.Keys(), []Key{"testKey1", "testKey2"})
+}
+
+func TestARCCacheIsScanResistant(t *testing.T) {
+	c := NewARC[Key, int](8)
+
+	for _, key := range []Key{"hot1", "hot2", "hot3"} {
+		c.Set(key, 0)
+		c.Get(key)
+	}
+
+	for i := 0; i < 100; i++ {
+		c.Set(Key("scan"+strconv.Itoa(i)), i)
+	}
+
+	for _, key := range []Key{"hot1", "hot2", "hot3"} {
+		assert.True(t, c.Contains(key))
+	}
+}
+
+func TestARCCacheAdaptsToRecentGhostHits(t *testing.T) {
+	c := NewARC[Key, int](4)
+
+	for i := 0; i < 5; i++ {
+		c.Set(Key(strconv.Itoa(i)), i)
+	}
+
+	assert.True(t, c.recentGhost.contains("0"))
+	assert.Equal(t, c.p, 0)
+
+	c.Set("0", 0)
+
+	assert.Equal(t, c.p, 1)
+	assert.True(t, c.frequent.contains("0"))
+	assert.False(t, c.recentGhost.contains("0"))
+}
+
+func TestARCCacheAdaptsToFrequentGhostHits(t *testing.T) {
+	c := NewARC[Key, int](2)
+	c.p = 1
+	c.Set("testKey1", 1)
+	c.Get("testKey1")
+	c.Set("testKey2", 2)
+	c.Get("testKey2")
+	c.Set("testKey3", 3)
+
+	assert.True(t, c.frequentGhost.contains("testKey1"))
+
+	c.Set("testKey1", 1)
+
+	assert.Equal(t, c.p, 0)
+	assert.True(t, c.frequent.contains("testKey1"))
+}
+
+func TestARCCacheStats(t *testing.T) {
+	c := NewARC[Key, string](4)
+	c.Set("testKey1", "testValue1")
+	c.Get("testKey1")
+	c.Get("nonexistentKey")
+
+	stats := c.Stats()
+
+	assert.Equal(t, stats.Len, 1)
+	assert.Equal(t, stats.Hits, uint64(1))
+	assert.Equal(t, stats.Misses, uint64(1))
+}
@@ -0,0 +1,168 @@
+package cache
+
+type arenaNode[T any] struct {
+	value T
+	prev  int32
+	next  int32
+}
+
+// arenaList is the implementation of doubly linked list which keeps all items in a single slice
+// and links them by indices instead of pointers. Slots of removed items are reused by new ones,
+// so once the list reached its working size, adding and removing items does not allocate memory.
+//
+// Items are addressed by indices returned from push operations. Zero index means no item.
+// Index of a removed item may be reused by the next pushed item.
+// Indices are not validated, so callers must not pass zero or index of a removed item.
+// The zero value of arenaList is an empty list ready to use.
+type arenaList[T any] struct {
+	// nodes[0] is a root of the ring, its next is front and its prev is back
+	nodes []arenaNode[T]
+	// free is head of list of free slots linked by next, zero if there are no free slots
+	free int32
+	len  int
+}
+
+// newArenaList returns pointer to newly created arenaList
+// with memory preallocated for given number of items.
+func newArenaList[T any](capacity int) *arenaList[T] {
+	l := &arenaList[T]{
+		nodes: make([]arenaNode[T], 1, capacity+1),
+	}
+
+	return l
+}
+
+// Init removes all items from the list keeping allocated memory.
+func (l *arenaList[T]) Init() *arenaList[T] {
+	// zero values, so removed items can be collected
+	clear(l.nodes)
+	if len(l.nodes) > 0 {
+		l.nodes = l.nodes[:1]
+	}
+	l.free = 0
+	l.len = 0
+
+	return l
+}
+
+// Len returns a length of the list.
+func (l *arenaList[T]) Len() int {
+	return l.len
+}
+
+// Front returns index of first item of the list.
+func (l *arenaList[T]) Front() int {
+	if len(l.nodes) == 0 {
+		return 0
+	}
+
+	return int(l.nodes[0].next)
+}
+
+// Back returns index of last item of the list.
+func (l *arenaList[T]) Back() int {
+	if len(l.nodes) == 0 {
+		return 0
+	}
+
+	return int(l.nodes[0].prev)
+}
+
+// Next returns index of item following item with given index.
+func (l *arenaList[T]) Next(i int) int {
+	return int(l.nodes[i].next)
+}
+
+// Prev returns index of item preceding item with given index.
+func (l *arenaList[T]) Prev(i int) int {
+	return int(l.nodes[i].prev)
+}
+
+// Value returns pointer to value of item with given index.
+// The pointer is valid until the next push to the list.
+func (l *arenaList[T]) Value(i int) *T {
+	return &l.nodes[i].value
+}
+
+// PushFront puts given value to the beginning of the list and returns its index.
+func (l *arenaList[T]) PushFront(v T) int {
+	i := l.alloc(v)
+	l.link(i, 0)
+
+	return int(i)
+}
+
+// PushBack puts given value to the end of the list and returns its index.
+func (l *arenaList[T]) PushBack(v T) int {
+	i := l.alloc(v)
+	l.link(i, l.nodes[0].prev)
+
+	return int(i)
+}
+
+// Remove removes item with given index from the list and frees its slot.
+func (l *arenaList[T]) Remove(i int) {
+	l.unlink(int32(i))
+
+	var zero T
+	l.nodes[i].value = zero
+	l.nodes[i].prev = 0
+	l.nodes[i].next = l.free
+	l.free = int32(i)
+	l.len--
+}
+
+// MoveToFront moves item with given index to the beginning of the list.
+func (l *arenaList[T]) MoveToFront(i int) {
+	if l.nodes[0].next == int32(i) {
+		return
+	}
+	l.unlink(int32(i))
+	l.link(int32(i), 0)
+}
+
+// MoveToBack moves item with given index to the end of the list.
+func (l *arenaList[T]) MoveToBack(i int) {
+	if l.nodes[0].prev == int32(i) {
+		return
+	}
+	l.unlink(int32(i))
+	l.link(int32(i), l.nodes[0].prev)
+}
+
+// alloc stores given value in a free slot, growing the slice only if there are no free slots.
+func (l *arenaList[T]) alloc(v T) int32 {
+	if len(l.nodes) == 0 {
+		l.nodes = make([]arenaNode[T], 1)
+	}
+
+	l.len++
+
+	if l.free != 0 {
+		i := l.free
+		l.free = l.nodes[i].next
+		l.nodes[i].value = v
+
+		return i
+	}
+
+	l.nodes = append(l.nodes, arenaNode[T]{value: v})
+
+	return int32(len(l.nodes) - 1)
+}
+
+// link puts item with given index right after item with prev index.
+func (l *arenaList[T]) link(i, prev int32) {
+	next := l.nodes[prev].next
+	l.nodes[i].prev = prev
+	l.nodes[i].next = next
+	l.nodes[prev].next = i
+	l.nodes[next].prev = i
+}
+
+// unlink detaches item with given index from its neighbours.
+func (l *arenaList[T]) unlink(i int32) {
+	prev, next := l.nodes[i].prev, l.nodes[i].next
+	l.nodes[prev].next = next
+	l.nodes[next].prev = prev
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func arenaListValues(list *arenaList[int]) []int {
+	values := make([]int, 0, list.Len())
+	for i := list.Front(); i != 0; i = list.Next(i) {
+		values = append(values, *list.Value(i))
+	}
+
+	return values
+}
+
+func arenaListBackwardValues(list *arenaList[int]) []int {
+	values := make([]int, 0, list.Len())
+	for i := list.Back(); i != 0; i = list.Prev(i) {
+		values = append(values, *list.Value(i))
+	}
+
+	return values
+}
+
+func assertArenaListValues(t *testing.T, list *arenaList[int], expected ...int) {
+	t.Helper()
+
+	reversed := make([]int, 0, len(expected))
+	for i := len(expected) - 1; i >= 0; i-- {
+		reversed = append(reversed, expected[i])
+	}
+
+	assert.Equal(t, list.Len(), len(expected))
+	assert.Equal(t, arenaListValues(list), append([]int{}, expected...))
+	assert.Equal(t, arenaListBackwardValues(list), reversed)
+}
+
+func TestArenaListZeroValue(t *testing.T) {
+	list := &arenaList[int]{}
+
+	assert.Equal(t, list.Front(), 0)
+	assert.Equal(t, list.Back(), 0)
+
+	list.Init()
+	list.PushBack(1)
+	list.PushFront(0)
+
+	assertArenaListValues(t, list, 0, 1)
+}
+
+func TestArenaListPush(t *testing.T) {
+	list := newArenaList[int](4)
+
+	list.PushFront(1)
+	list.PushBack(2)
+	list.PushFront(0)
+
+	assertArenaListValues(t, list, 0, 1, 2)
+}
+
+func TestArenaListRemove(t *testing.T) {
+	list := newArenaList[int](4)
+
+	first := list.PushBack(0)
+	middle := list.PushBack(1)
+	last := list.PushBack(2)
+
+	list.Remove(middle)
+	assertArenaListValues(t, list, 0, 2)
+
+	list.Remove(first)
+	assertArenaListValues(t, list, 2)
+
+	list.Remove(last)
+	assertArenaListValues(t, list)
+}
+
+func TestArenaListReusesFreeSlots(t *testing.T) {
+	list := newArenaList[int](2)
+
+	list.PushBack(0)
+	i := list.PushBack(1)
+	list.Remove(i)
+
+	assert.Equal(t, list.PushFront(2), i)
+	assert.Equal(t, len(list.nodes), 3)
+	assertArenaListValues(t, list, 2, 0)
+}
+
+func TestArenaListMove(t *testing.T) {
+	list := newArenaList[int](3)
+
+	first := list.PushBack(0)
+	list.PushBack(1)
+	last := list.PushBack(2)
+
+	list.MoveToFront(last)
+	assertArenaListValues(t, list, 2, 0, 1)
+
+	list.MoveToFront(last)
+	assertArenaListValues(t, list, 2, 0, 1)
+
+	list.MoveToBack(first)
+	assertArenaListValues(t, list, 2, 1, 0)
+
+	list.MoveToBack(first)
+	assertArenaListValues(t, list, 2, 1, 0)
+}
+
+func TestArenaListInit(t *testing.T) {
+	list := newArenaList[int](3)
+
+	list.PushBack(0)
+	list.PushBack(1)
+	list.Remove(list.Front())
+
+	list.Init()
+	assertArenaListValues(t, list)
+
+	list.PushBack(2)
+	assertArenaListValues(t, list, 2)
+}
+
+func TestArenaListDoesNotAllocate(t *testing.T) {
+	list := newArenaList[int](10)
+	for i := 0; i < 10; i++ {
+		list.PushBack(i)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		list.Remove(list.Back())
+		list.MoveToBack(list.Front())
+		list.PushFront(0)
+	})
+
+	assert.Equal(t, allocs, 0.0)
+}
+
+func TestLRUCacheSteadyStateDoesNotAllocate(t *testing.T) {
+	const capacity = 1024
+
+	c := New[int, int](capacity)
+	for i := 0; i < capacity; i++ {
+		c.Set(i, i)
+	}
+
+	key := capacity
+	allocs := testing.AllocsPerRun(1000, func() {
+		// new key evicts the least recently used one
+		c.Set(key, key)
+		c.Get(key - capacity/2)
+		c.Get(key + 1)
+		key++
+	})
+
+	assert.Equal(t, allocs, 0.0)
+	assert.Equal(t, c.Len(), capacity)
+}
+
+func BenchmarkLRUCacheSetFull(b *testing.B) {
+	const capacity = 1024
+
+	c := New[int, int](capacity)
+	for i := 0; i < capacity; i++ {
+		c.Set(i, i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c.Set(capacity+i, i)
+	}
+}
+
+func BenchmarkLRUCacheGet(b *testing.B) {
+	const capacity = 1024
+
+	c := New[int, int](capacity)
+	for i := 0; i < capacity; i++ {
+		c.Set(i, i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c.Get(i % capacity)
+	}
+}
@@ -1,85 +1,517 @@
 // Package cache implements LRU cache based on doubly linked list.
+//
+// Types of the package are generic, so code written before they became generic has to name
+// LRUCache, Cache, DoublyLinkedList and ListItem with type arguments, which is a breaking change.
+// Such code may use AnyLRUCache, AnyCache, AnyList and AnyListItem aliases and NewLRUCache instead,
+// keeping keys of type Key and values of type interface{}.
 package cache
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
 type Key string
 
-type cacheItem struct {
-	key   Key
-	value interface{}
+var ErrTooLarge = errors.New("item is larger than the cache")
+
+// Unlimited is capacity of a cache whose number of entries is not limited,
+// for example when it is bounded by total cost of entries only.
+const Unlimited = math.MaxInt
+
+// preallocated returns number of entries storage of a cache with given capacity is allocated for.
+func preallocated(capacity int) int {
+	if capacity == Unlimited {
+		return 0
+	}
+
+	return max(capacity, 0)
+}
+
+type cacheItem[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+	cost      int64
+}
+
+// expired reports whether item is expired at given moment.
+// Items with zero expiration time never expire.
+func (i *cacheItem[K, V]) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && !now.Before(i.expiresAt)
 }
 
-type Cache interface {
-	Set(key Key, value interface{}) (bool, error)
-	Get(key Key) (interface{}, bool)
+type Cache[K comparable, V any] interface {
+	Set(key K, value V) (bool, error)
+	Get(key K) (V, bool)
+	Peek(key K) (V, bool)
+	Contains(key K) bool
+	Delete(key K) bool
+	Len() int
+	Cap() int
+	Keys() []K
+	Resize(capacity int) int
 	Clear()
 }
 
+var _ Cache[Key, interface{}] = (*LRUCache[Key, interface{}])(nil)
+
 // LRUCache is an implementation of a cache with the least recently used policy.
-// It implements Cache interface.
-type LRUCache struct {
-	capacity int
-	queue    DoublyLinkedList
-	storage  map[Key]*ListItem
+// It implements Cache interface. It is safe for concurrent use.
+//
+// The cache is bounded by number of entries, by total cost of entries, or by both.
+// Capacity Unlimited means number of entries is not limited, cache of zero capacity stores nothing.
+type LRUCache[K comparable, V any] struct {
+	mu         sync.Mutex
+	capacity   int
+	maxCost    int64
+	cost       int64
+	sizer      Sizer[V]
+	queue      *arenaList[cacheItem[K, V]]
+	storage    map[K]int
+	defaultTTL time.Duration
+	clock      Clock
+	stop       chan struct{}
+	stopOnce   sync.Once
+	onEvict    EvictFunc[K, V]
+	// notifyReplaced makes onEvict receive values overwritten by Set
+	notifyReplaced bool
+	// spill receives evictions together with expiration time, it is used by TieredCache
+	spill    func(e eviction[K, V])
+	counters counters
+	loads    loadGroup[K, V]
+	// evictions collected under mutex, reported after it is released
+	evictions []eviction[K, V]
 }
 
-// NewLRUCache returns pointer to newly created LRUCache type with given capacity.
-func NewLRUCache(capacity int) *LRUCache {
-	return &LRUCache{
-		capacity: capacity,
-		queue:    DoublyLinkedList{},
-		storage:  make(map[Key]*ListItem, capacity),
+// New returns pointer to newly created LRUCache with given capacity
+// and arbitrary comparable keys and values of any type.
+// New panics if sizer passed with WithSizer does not accept values of type V.
+func New[K comparable, V any](capacity int, opts ...Option) *LRUCache[K, V] {
+	o := newOptions(opts)
+
+	var sizer Sizer[V]
+	if o.sizer != nil {
+		var ok bool
+		if sizer, ok = o.sizer.(Sizer[V]); !ok {
+			panic(fmt.Sprintf("cache: sizer of type %T does not match cache values", o.sizer))
+		}
+	}
+
+	if capacity < 0 {
+		capacity = 0
+	}
+
+	c := &LRUCache[K, V]{
+		capacity:       capacity,
+		maxCost:        max(o.maxCost, 0),
+		sizer:          sizer,
+		queue:          newArenaList[cacheItem[K, V]](preallocated(capacity)),
+		storage:        make(map[K]int, preallocated(capacity)),
+		defaultTTL:     o.defaultTTL,
+		clock:          o.clock,
+		stop:           make(chan struct{}),
+		loads:          loadGroup[K, V]{errorTTL: o.errorTTL},
+		notifyReplaced: o.notifyReplaced,
 	}
+
+	if o.janitorInterval > 0 {
+		go c.runJanitor(o.janitorInterval)
+	}
+
+	return c
+}
+
+// AnyCache is Cache with keys of type Key and values of any type, as it was before it became generic.
+type AnyCache = Cache[Key, interface{}]
+
+// AnyLRUCache is LRUCache with keys of type Key and values of any type, as it was before it became generic.
+type AnyLRUCache = LRUCache[Key, interface{}]
+
+// NewLRUCache returns pointer to newly created LRUCache type with given capacity.
+// It is kept for compatibility with code written before the cache became generic.
+func NewLRUCache(capacity int, opts ...Option) *AnyLRUCache {
+	return New[Key, interface{}](capacity, opts...)
 }
 
-// Set stores key with given value.
+// Set stores key with given value using default time to live of the cache.
 // Sets return boolean indicating existence of a given key in the cache.
-func (c *LRUCache) Set(key Key, value interface{}) bool {
-	listItem, ok := c.storage[key]
+func (c *LRUCache[K, V]) Set(key K, value V) (bool, error) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	return c.set(key, value, c.defaultTTL, c.costOf(value))
+}
+
+// SetWithTTL stores key with given value, which expires after given ttl.
+// Non-positive ttl means the value never expires.
+// SetWithTTL returns boolean indicating existence of a given key in the cache.
+func (c *LRUCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	return c.set(key, value, ttl, c.costOf(value))
+}
+
+// SetWithCost stores key with given value and cost using default time to live of the cache.
+// Given cost is used instead of the one computed by sizer of the cache.
+// SetWithCost returns ErrTooLarge if cost exceeds maximum cost of the cache.
+func (c *LRUCache[K, V]) SetWithCost(key K, value V, cost int64) (bool, error) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	return c.set(key, value, c.defaultTTL, cost)
+}
+
+// set stores key with given value, ttl and cost.
+// It must be called with mutex held.
+func (c *LRUCache[K, V]) set(key K, value V, ttl time.Duration, cost int64) (bool, error) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.clock.Now().Add(ttl)
+	}
+
+	return c.setUntil(key, value, expiresAt, cost)
+}
+
+// setUntil stores key with given value and cost, which expires at given moment.
+// Zero expiration time means the value never expires.
+// It must be called with mutex held.
+func (c *LRUCache[K, V]) setUntil(key K, value V, expiresAt time.Time, cost int64) (bool, error) {
+	if c.maxCost > 0 && cost > c.maxCost {
+		return false, fmt.Errorf("%w: cost %d, maximum cost %d", ErrTooLarge, cost, c.maxCost)
+	}
+
+	now := c.clock.Now()
+	i, ok := c.storage[key]
 
 	// if element with given key already in cache
 	if ok {
-		cachedItem := listItem.Value.(*cacheItem)
-		cachedItem.value = value
-		c.queue.MoveToFront(listItem)
+		item := c.queue.Value(i)
+		existed := !item.expired(now)
+		if !existed {
+			c.evicted(item, EvictExpired)
+		} else if c.notifyReplaced {
+			c.evicted(item, EvictReplaced)
+		}
+		c.cost += cost - item.cost
+		item.value = value
+		item.expiresAt = expiresAt
+		item.cost = cost
+		c.queue.MoveToFront(i)
+		c.evictOverflow()
+		c.counters.sets.Add(1)
+
+		return existed, nil
+	}
+
+	item := cacheItem[K, V]{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+		cost:      cost,
+	}
+	c.counters.sets.Add(1)
+
+	// cache of zero capacity keeps nothing, so new entry is evicted at once
+	if c.capacity == 0 {
+		c.evicted(&item, EvictCapacity)
+		c.counters.evictions.Add(1)
 
-		return true
+		return false, nil
 	}
 
 	// if cache is full
-	if c.queue.Len() == c.capacity {
-		lastItem := c.queue.Back()
-		cachedItem := lastItem.Value.(*cacheItem)
-		c.queue.Remove(lastItem)
-		delete(c.storage, cachedItem.key)
+	if c.queue.Len() >= c.capacity {
+		c.remove(c.queue.Back(), EvictCapacity)
 	}
 
-	c.storage[key] = c.queue.PushFront(
-		&cacheItem{
-			key:   key,
-			value: value,
-		},
-	)
+	c.storage[key] = c.queue.PushFront(item)
+	c.cost += cost
+	c.evictOverflow()
 
-	return false
+	return false, nil
 }
 
 // Get returns value associated with a given key in the cache and
 // boolean indicating existence of key in the cache.
-func (c *LRUCache) Get(key Key) (interface{}, bool) {
-	listItem, ok := c.storage[key]
+// Expired entries are treated as missing and removed from the cache.
+func (c *LRUCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	var zero V
+	i, ok := c.storage[key]
+
+	if !ok {
+		c.counters.misses.Add(1)
+		return zero, false
+	}
 
+	if c.queue.Value(i).expired(c.clock.Now()) {
+		c.remove(i, EvictExpired)
+		c.counters.misses.Add(1)
+		return zero, false
+	}
+
+	c.queue.MoveToFront(i)
+	c.counters.hits.Add(1)
+
+	return c.queue.Value(i).value, true
+}
+
+// Peek returns value associated with a given key in the cache and
+// boolean indicating existence of key in the cache without updating its recency.
+func (c *LRUCache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	var zero V
+	i, ok := c.storage[key]
+
+	if !ok || c.queue.Value(i).expired(c.clock.Now()) {
+		return zero, false
+	}
+
+	return c.queue.Value(i).value, true
+}
+
+// Contains reports whether given key exists in the cache without updating its recency.
+func (c *LRUCache[K, V]) Contains(key K) bool {
+	_, ok := c.Peek(key)
+
+	return ok
+}
+
+// Delete removes given key from the cache.
+// Delete returns boolean indicating existence of a given key in the cache.
+func (c *LRUCache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	i, ok := c.storage[key]
 	if !ok {
-		return nil, false
+		return false
+	}
+
+	if c.queue.Value(i).expired(c.clock.Now()) {
+		c.remove(i, EvictExpired)
+		return false
+	}
+
+	c.remove(i, EvictDeleted)
+
+	return true
+}
+
+// Clear removes all data in the cache including remembered loader errors.
+func (c *LRUCache[K, V]) Clear() {
+	c.loads.clearFailures()
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.clear()
+}
+
+// clear removes all entries, recording them as cleared. It must be called with c.mu held.
+func (c *LRUCache[K, V]) clear() {
+	if c.onEvict != nil {
+		for i := c.queue.Front(); i != 0; i = c.queue.Next(i) {
+			c.evicted(c.queue.Value(i), EvictCleared)
+		}
+	}
+
+	c.queue.Init()
+	c.storage = make(map[K]int, preallocated(c.capacity))
+	c.cost = 0
+}
+
+// Len returns number of entries in the cache.
+// Expired entries which are not removed yet are counted too.
+func (c *LRUCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.queue.Len()
+}
+
+// Cap returns capacity of the cache.
+func (c *LRUCache[K, V]) Cap() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.capacity
+}
+
+// Keys returns keys of not expired entries from the most to the least recently used.
+func (c *LRUCache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	keys := make([]K, 0, c.queue.Len())
+
+	for i := c.queue.Front(); i != 0; i = c.queue.Next(i) {
+		if item := c.queue.Value(i); !item.expired(now) {
+			keys = append(keys, item.key)
+		}
+	}
+
+	return keys
+}
+
+// Stats returns snapshot of the cache state.
+func (c *LRUCache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{
+		Len:     c.queue.Len(),
+		Cost:    c.cost,
+		MaxCost: c.maxCost,
+	}
+	c.counters.fill(&stats)
+
+	return stats
+}
+
+// Resize changes capacity of the cache, evicting the least recently used entries
+// which do not fit into new capacity. Non-positive capacity evicts all entries,
+// Unlimited removes the limit. Resize returns number of evicted entries.
+func (c *LRUCache[K, V]) Resize(capacity int) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if capacity < 0 {
+		capacity = 0
 	}
+	c.capacity = capacity
 
-	cachedItem := listItem.Value.(*cacheItem)
-	c.queue.MoveToFront(listItem)
+	evicted := 0
+	for c.queue.Len() > capacity {
+		c.remove(c.queue.Back(), EvictCapacity)
+		evicted++
+	}
+
+	return evicted
+}
+
+// DeleteExpired removes all expired entries from the cache.
+func (c *LRUCache[K, V]) DeleteExpired() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	now := c.clock.Now()
+
+	for i := c.queue.Front(); i != 0; {
+		next := c.queue.Next(i)
+		if c.queue.Value(i).expired(now) {
+			c.remove(i, EvictExpired)
+		}
+		i = next
+	}
+}
+
+// OnEvict registers function called for every entry leaving the cache
+// together with the reason of the eviction. The function is called after
+// internal lock is released, so it may safely use the cache.
+// Values overwritten by Set are reported only if the cache is created with WithNotifyReplaced.
+func (c *LRUCache[K, V]) OnEvict(fn EvictFunc[K, V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onEvict = fn
+}
+
+// Stop stops background janitor of the cache, if any.
+// It is safe to call Stop multiple times.
+func (c *LRUCache[K, V]) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+func (c *LRUCache[K, V]) runJanitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.DeleteExpired()
+		}
+	}
+}
+
+// remove deletes item with given index from both queue and storage.
+// It must be called with mutex held.
+func (c *LRUCache[K, V]) remove(i int, reason EvictReason) {
+	item := c.queue.Value(i)
+	delete(c.storage, item.key)
+	c.cost -= item.cost
+	c.evicted(item, reason)
+	c.queue.Remove(i)
+
+	if reason == EvictCapacity {
+		c.counters.evictions.Add(1)
+	} else if reason == EvictExpired {
+		c.counters.expirations.Add(1)
+	}
+}
+
+// evictOverflow removes the least recently used entries until total cost fits maximum cost.
+// It must be called with mutex held.
+func (c *LRUCache[K, V]) evictOverflow() {
+	for c.maxCost > 0 && c.cost > c.maxCost {
+		c.remove(c.queue.Back(), EvictCapacity)
+	}
+}
 
-	return cachedItem.value, true
+// costOf returns cost of given value computed by sizer of the cache.
+// Values cost nothing if cache has no sizer.
+func (c *LRUCache[K, V]) costOf(value V) int64 {
+	if c.sizer == nil {
+		return 0
+	}
+
+	return c.sizer(value)
+}
+
+// evicted remembers evicted item to report it once mutex is released.
+// It must be called with mutex held.
+func (c *LRUCache[K, V]) evicted(item *cacheItem[K, V], reason EvictReason) {
+	if c.onEvict == nil && c.spill == nil {
+		return
+	}
+
+	c.evictions = append(c.evictions, eviction[K, V]{
+		key:       item.key,
+		value:     item.value,
+		expiresAt: item.expiresAt,
+		reason:    reason,
+	})
 }
 
-// Clear removes all data in the cache.
-func (c *LRUCache) Clear() {
-	c.queue = DoublyLinkedList{}
-	c.storage = make(map[Key]*ListItem, c.capacity)
+// unlock releases mutex and reports evictions collected while it was held.
+func (c *LRUCache[K, V]) unlock() {
+	evictions := c.evictions
+	onEvict := c.onEvict
+	spill := c.spill
+	c.evictions = nil
+	c.mu.Unlock()
+
+	for _, e := range evictions {
+		if spill != nil {
+			spill(e)
+		}
+		if onEvict != nil {
+			onEvict(e.key, e.value, e.reason)
+		}
+	}
 }
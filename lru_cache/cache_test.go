@@ -1,19 +1,59 @@
 package cache
 
 import (
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func assertQueueFrontValue(t *testing.T, c *LRUCache, expectedValue string) {
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func mustSet(t *testing.T, c Cache[Key, interface{}], key Key, value interface{}) bool {
+	t.Helper()
+	existed, err := c.Set(key, value)
+	require.NoError(t, err)
+
+	return existed
+}
+
+// sizeStats drops counters from stats snapshot, leaving only size related fields.
+func sizeStats(s Stats) Stats {
+	return Stats{Len: s.Len, Cost: s.Cost, MaxCost: s.MaxCost}
+}
+
+func assertQueueFrontValue(t *testing.T, c *LRUCache[Key, interface{}], expectedValue string) {
 	t.Helper()
-	cachedItem := c.queue.Front().Value.(*cacheItem)
+	cachedItem := c.queue.Value(c.queue.Front())
 
 	assert.Equal(t, cachedItem.value, expectedValue)
 }
 
-func assertKeyDoesNotExist(t *testing.T, c *LRUCache, key Key) {
+func assertKeyDoesNotExist(t *testing.T, c Cache[Key, interface{}], key Key) {
 	t.Helper()
 	value, ok := c.Get(key)
 
@@ -24,14 +64,14 @@ func assertKeyDoesNotExist(t *testing.T, c *LRUCache, key Key) {
 func TestLRUCacheSetResultWithNewElement(t *testing.T) {
 	c := NewLRUCache(5)
 
-	assert.False(t, c.Set("testKey", "testValue"))
+	assert.False(t, mustSet(t, c, "testKey", "testValue"))
 }
 
 func TestLRUCacheSetResultWithExistingElement(t *testing.T) {
 	c := NewLRUCache(5)
 	c.Set("testKey", "testValue")
 
-	assert.True(t, c.Set("testKey", "newTestValue"))
+	assert.True(t, mustSet(t, c, "testKey", "newTestValue"))
 
 	result, ok := c.Get("testKey")
 
@@ -108,3 +148,417 @@ func TestLRUCacheClear(t *testing.T) {
 
 	assertKeyDoesNotExist(t, c, "testKey")
 }
+
+type testUserID struct {
+	tenant int
+	id     int
+}
+
+type testUser struct {
+	name string
+}
+
+func TestLRUCacheGenericKeysAndValues(t *testing.T) {
+	c := New[testUserID, testUser](2)
+	c.Set(testUserID{tenant: 1, id: 1}, testUser{name: "first"})
+	c.Set(testUserID{tenant: 2, id: 1}, testUser{name: "second"})
+
+	user, ok := c.Get(testUserID{tenant: 1, id: 1})
+
+	assert.True(t, ok)
+	assert.Equal(t, user.name, "first")
+
+	c.Set(testUserID{tenant: 3, id: 1}, testUser{name: "third"})
+
+	user, ok = c.Get(testUserID{tenant: 2, id: 1})
+
+	assert.False(t, ok)
+	assert.Equal(t, user, testUser{})
+}
+
+func TestLRUCacheGenericIntKeys(t *testing.T) {
+	c := New[int, []byte](5)
+	c.Set(42, []byte("answer"))
+
+	value, ok := c.Get(42)
+
+	assert.True(t, ok)
+	assert.Equal(t, value, []byte("answer"))
+}
+
+func TestNonGenericAliases(t *testing.T) {
+	var c AnyCache = NewLRUCache(2)
+
+	mustSet(t, c, "testKey", 1)
+	value, ok := c.Get("testKey")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, 1)
+
+	var list AnyList
+	var item *AnyListItem
+	item = list.PushFront("testValue")
+
+	assert.Equal(t, list.Front(), item)
+
+	lru, ok := c.(*AnyLRUCache)
+
+	assert.True(t, ok)
+	assert.Equal(t, lru.Len(), 1)
+}
+
+func TestLRUCacheSetWithTTL(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(5, WithClock(clock))
+	c.SetWithTTL("testKey", "testValue", time.Minute)
+
+	clock.Advance(59 * time.Second)
+
+	value, ok := c.Get("testKey")
+
+	assert.Equal(t, value, "testValue")
+	assert.True(t, ok)
+
+	clock.Advance(time.Second)
+
+	assertKeyDoesNotExist(t, c, "testKey")
+	// expired item is purged on access
+	assert.Equal(t, c.queue.Len(), 0)
+	assert.NotContains(t, c.storage, Key("testKey"))
+}
+
+func TestLRUCacheDefaultTTL(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(5, WithClock(clock), WithDefaultTTL(time.Minute))
+	c.Set("testKey1", "testValue1")
+	c.SetWithTTL("testKey2", "testValue2", 0)
+
+	clock.Advance(time.Hour)
+
+	assertKeyDoesNotExist(t, c, "testKey1")
+
+	value, ok := c.Get("testKey2")
+
+	assert.Equal(t, value, "testValue2")
+	assert.True(t, ok)
+}
+
+func TestLRUCacheSetRefreshesTTL(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(5, WithClock(clock), WithDefaultTTL(time.Minute))
+	c.Set("testKey", "testValue")
+
+	clock.Advance(30 * time.Second)
+
+	assert.True(t, mustSet(t, c, "testKey", "newTestValue"))
+
+	clock.Advance(45 * time.Second)
+
+	value, ok := c.Get("testKey")
+
+	assert.Equal(t, value, "newTestValue")
+	assert.True(t, ok)
+}
+
+func TestLRUCacheSetExpiredKey(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(5, WithClock(clock))
+	c.SetWithTTL("testKey", "testValue", time.Minute)
+
+	clock.Advance(time.Minute)
+
+	// expired key is reported as nonexistent
+	assert.False(t, mustSet(t, c, "testKey", "newTestValue"))
+}
+
+func TestLRUCacheDeleteExpired(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(5, WithClock(clock))
+	c.SetWithTTL("testKey1", "testValue1", time.Minute)
+	c.SetWithTTL("testKey2", "testValue2", time.Hour)
+	c.SetWithTTL("testKey3", "testValue3", time.Minute)
+
+	clock.Advance(time.Minute)
+	c.DeleteExpired()
+
+	assert.Equal(t, c.queue.Len(), 1)
+	assert.Len(t, c.storage, 1)
+	assertQueueFrontValue(t, c, "testValue2")
+}
+
+func TestLRUCacheJanitor(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(5, WithClock(clock), WithJanitor(time.Millisecond))
+	defer c.Stop()
+
+	c.SetWithTTL("testKey", "testValue", time.Minute)
+	clock.Advance(time.Minute)
+
+	assert.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return c.queue.Len() == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestLRUCacheStopIsIdempotent(t *testing.T) {
+	c := NewLRUCache(5, WithJanitor(time.Millisecond))
+	c.Stop()
+	c.Stop()
+}
+
+func TestLRUCachePeek(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+
+	value, ok := c.Peek("testKey1")
+
+	assert.Equal(t, value, "testValue1")
+	assert.True(t, ok)
+	// peek does not promote element
+	assertQueueFrontValue(t, c, "testValue2")
+
+	value, ok = c.Peek("nonexistentKey")
+
+	assert.Nil(t, value)
+	assert.False(t, ok)
+}
+
+func TestLRUCacheContains(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(2, WithClock(clock))
+	c.Set("testKey1", "testValue1")
+	c.SetWithTTL("testKey2", "testValue2", time.Minute)
+
+	assert.True(t, c.Contains("testKey1"))
+	assert.False(t, c.Contains("nonexistentKey"))
+	// contains does not promote element
+	assertQueueFrontValue(t, c, "testValue2")
+
+	clock.Advance(time.Minute)
+
+	assert.False(t, c.Contains("testKey2"))
+}
+
+func TestLRUCacheDelete(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+
+	assert.True(t, c.Delete("testKey1"))
+	assert.False(t, c.Delete("testKey1"))
+	assert.Equal(t, c.Len(), 1)
+	assertKeyDoesNotExist(t, c, "testKey1")
+}
+
+func TestLRUCacheLenAndCap(t *testing.T) {
+	c := NewLRUCache(3)
+
+	assert.Equal(t, c.Len(), 0)
+	assert.Equal(t, c.Cap(), 3)
+
+	for _, key := range []Key{"testKey1", "testKey2", "testKey3", "testKey4"} {
+		c.Set(key, "testValue")
+	}
+
+	assert.Equal(t, c.Len(), 3)
+	assert.Equal(t, c.Cap(), 3)
+}
+
+func TestLRUCacheKeys(t *testing.T) {
+	c := NewLRUCache(3)
+
+	assert.Empty(t, c.Keys())
+
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Set("testKey3", "testValue3")
+	c.Get("testKey1")
+
+	assert.Equal(t, c.Keys(), []Key{"testKey1", "testKey3", "testKey2"})
+}
+
+func TestLRUCacheResize(t *testing.T) {
+	c := NewLRUCache(4)
+	evicted := recordEvictions(c)
+
+	for _, key := range []Key{"testKey1", "testKey2", "testKey3", "testKey4"} {
+		c.Set(key, "testValue")
+	}
+
+	assert.Equal(t, c.Resize(2), 2)
+	assert.Equal(t, c.Cap(), 2)
+	assert.Equal(t, c.Keys(), []Key{"testKey4", "testKey3"})
+	assert.Len(t, *evicted, 2)
+	assert.Equal(t, (*evicted)[0].reason, EvictCapacity)
+
+	assert.Equal(t, c.Resize(10), 0)
+
+	c.Set("testKey5", "testValue")
+	c.Set("testKey6", "testValue")
+	c.Set("testKey7", "testValue")
+
+	assert.Equal(t, c.Len(), 5)
+}
+
+func TestLRUCacheZeroCapacityStoresNothing(t *testing.T) {
+	c := NewLRUCache(0)
+	evicted := recordEvictions(c)
+
+	existed, err := c.Set("testKey1", "testValue1")
+
+	require.NoError(t, err)
+	assert.False(t, existed)
+	assert.Equal(t, c.Len(), 0)
+	assertKeyDoesNotExist(t, c, "testKey1")
+	// the entry is dropped for lack of capacity like any other
+	assert.Equal(t, *evicted, []evictedEntry{{"testKey1", "testValue1", EvictCapacity}})
+
+	c = NewLRUCache(2)
+	c.Set("testKey1", "testValue1")
+	c.Resize(0)
+	c.Set("testKey2", "testValue2")
+
+	assert.Equal(t, c.Len(), 0)
+	assert.Equal(t, c.Cap(), 0)
+}
+
+func TestLRUCacheResizeToZero(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("testKey1", "testValue1")
+
+	assert.Equal(t, c.Resize(-1), 1)
+	assert.Equal(t, c.Cap(), 0)
+	assert.Equal(t, c.Len(), 0)
+}
+
+func TestLRUCacheUnlimitedCapacity(t *testing.T) {
+	c := NewLRUCache(Unlimited)
+
+	for i := 0; i < 100; i++ {
+		c.Set(Key(strconv.Itoa(i)), i)
+	}
+
+	assert.Equal(t, c.Len(), 100)
+
+	assert.Equal(t, c.Resize(10), 90)
+	assert.Equal(t, c.Resize(Unlimited), 0)
+	assert.Equal(t, c.Cap(), Unlimited)
+}
+
+func TestLRUCacheMaxCost(t *testing.T) {
+	c := NewLRUCache(Unlimited, WithMaxCost(10))
+	evicted := recordEvictions(c)
+
+	_, err := c.SetWithCost("testKey1", "testValue1", 4)
+	require.NoError(t, err)
+	_, err = c.SetWithCost("testKey2", "testValue2", 4)
+	require.NoError(t, err)
+	c.Get("testKey1")
+
+	assert.Equal(t, sizeStats(c.Stats()), Stats{Len: 2, Cost: 8, MaxCost: 10})
+
+	_, err = c.SetWithCost("testKey3", "testValue3", 5)
+	require.NoError(t, err)
+
+	assert.Equal(t, c.Keys(), []Key{"testKey3", "testKey1"})
+	assert.Equal(t, sizeStats(c.Stats()), Stats{Len: 2, Cost: 9, MaxCost: 10})
+	assert.Equal(t, *evicted, []evictedEntry{{"testKey2", "testValue2", EvictCapacity}})
+}
+
+func TestLRUCacheMaxCostEvictsSeveralItems(t *testing.T) {
+	c := NewLRUCache(Unlimited, WithMaxCost(10))
+
+	for _, key := range []Key{"testKey1", "testKey2", "testKey3"} {
+		c.SetWithCost(key, "testValue", 3)
+	}
+
+	c.SetWithCost("testKey4", "testValue", 10)
+
+	assert.Equal(t, c.Keys(), []Key{"testKey4"})
+	assert.Equal(t, c.Stats().Cost, int64(10))
+}
+
+func TestLRUCacheMaxCostUpdateExistingItem(t *testing.T) {
+	c := NewLRUCache(Unlimited, WithMaxCost(10))
+	c.SetWithCost("testKey1", "testValue1", 4)
+	c.SetWithCost("testKey2", "testValue2", 4)
+
+	existed, err := c.SetWithCost("testKey2", "newTestValue2", 7)
+
+	require.NoError(t, err)
+	assert.True(t, existed)
+	assert.Equal(t, c.Keys(), []Key{"testKey2"})
+	assert.Equal(t, c.Stats().Cost, int64(7))
+
+	c.Delete("testKey2")
+
+	assert.Equal(t, c.Stats().Cost, int64(0))
+}
+
+func TestLRUCacheMaxCostRejectsTooLargeItem(t *testing.T) {
+	c := NewLRUCache(Unlimited, WithMaxCost(10))
+	c.SetWithCost("testKey", "testValue", 5)
+
+	existed, err := c.SetWithCost("testKey", "newTestValue", 11)
+
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.False(t, existed)
+
+	// previous value is kept
+	value, ok := c.Get("testKey")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, "testValue")
+}
+
+func TestLRUCacheMaxCostWithCapacity(t *testing.T) {
+	c := NewLRUCache(2, WithMaxCost(100))
+
+	for _, key := range []Key{"testKey1", "testKey2", "testKey3"} {
+		c.SetWithCost(key, "testValue", 1)
+	}
+
+	assert.Equal(t, c.Keys(), []Key{"testKey3", "testKey2"})
+	assert.Equal(t, c.Stats().Cost, int64(2))
+}
+
+func TestLRUCacheSizer(t *testing.T) {
+	c := New[Key, []byte](Unlimited, WithMaxCost(10), WithSizer(func(value []byte) int64 {
+		return int64(len(value))
+	}))
+
+	c.Set("testKey1", []byte("12345"))
+	c.Set("testKey2", []byte("123"))
+
+	assert.Equal(t, sizeStats(c.Stats()), Stats{Len: 2, Cost: 8, MaxCost: 10})
+
+	_, err := c.Set("testKey3", []byte("12345678901"))
+
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	c.Set("testKey3", []byte("1234"))
+
+	assert.Equal(t, c.Keys(), []Key{"testKey3", "testKey2"})
+	assert.Equal(t, c.Stats().Cost, int64(7))
+	// explicit cost overrides sizer
+	c.SetWithCost("testKey2", []byte("123"), 6)
+
+	assert.Equal(t, c.Stats().Cost, int64(10))
+}
+
+func TestLRUCacheClearResetsCost(t *testing.T) {
+	c := NewLRUCache(Unlimited, WithMaxCost(10))
+	c.SetWithCost("testKey", "testValue", 5)
+	c.Clear()
+
+	assert.Equal(t, c.Stats().Cost, int64(0))
+}
+
+func TestNewPanicsOnSizerTypeMismatch(t *testing.T) {
+	assert.Panics(t, func() {
+		New[Key, string](Unlimited, WithSizer(func(value []byte) int64 { return 0 }))
+	})
+}
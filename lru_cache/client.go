@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnexpectedResponse = errors.New("unexpected response of cache server")
+
+// DefaultClientTimeout is time limit of a request of Client created without HTTP client.
+const DefaultClientTimeout = 5 * time.Second
+
+var _ Cache[string, []byte] = (*Client)(nil)
+
+// Client is a cache hosted by remote server serving Handler.
+// It implements Cache interface, so it can replace local cache without code changes.
+//
+// Methods which have no error result treat failed requests as missing keys,
+// like a cache which lost its contents. Set reports such failures.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns pointer to newly created Client of server with given base URL.
+// If httpClient is nil, HTTP client with DefaultClientTimeout is used.
+// Given httpClient should have a timeout, so unresponsive server does not block callers forever.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultClientTimeout}
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// Set stores key with given value on the server.
+// Set returns boolean indicating existence of a given key in the cache.
+func (c *Client) Set(key string, value []byte) (bool, error) {
+	resp, err := c.do(http.MethodPut, c.keyURL(key), value)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusCreated:
+		return false, nil
+	case http.StatusRequestEntityTooLarge:
+		return false, fmt.Errorf("%w: %s", ErrTooLarge, readMessage(resp))
+	default:
+		return false, unexpectedResponse(resp)
+	}
+}
+
+// Get returns value associated with a given key on the server and
+// boolean indicating existence of key in the cache.
+func (c *Client) Get(key string) ([]byte, bool) {
+	return c.get(c.keyURL(key))
+}
+
+// Peek returns value associated with a given key on the server and
+// boolean indicating existence of key in the cache without updating its recency.
+func (c *Client) Peek(key string) ([]byte, bool) {
+	return c.get(c.keyURL(key) + "?peek=true")
+}
+
+// Contains reports whether given key exists on the server without updating its recency.
+func (c *Client) Contains(key string) bool {
+	return c.status(http.MethodHead, c.keyURL(key)) == http.StatusOK
+}
+
+// Delete removes given key from the server.
+// Delete returns boolean indicating existence of a given key in the cache.
+func (c *Client) Delete(key string) bool {
+	return c.status(http.MethodDelete, c.keyURL(key)) == http.StatusNoContent
+}
+
+// Len returns number of entries on the server.
+func (c *Client) Len() int {
+	stats, _ := c.ServerStats()
+
+	return stats.Len
+}
+
+// Cap returns capacity of the cache on the server.
+func (c *Client) Cap() int {
+	stats, _ := c.ServerStats()
+
+	return stats.Cap
+}
+
+// Keys returns keys of entries on the server from the most to the least recently used.
+func (c *Client) Keys() []string {
+	var keys []string
+	if err := c.getJSON(http.MethodGet, c.baseURL+"/keys", &keys); err != nil {
+		return []string{}
+	}
+
+	return keys
+}
+
+// Resize changes capacity of the cache on the server.
+// Resize returns number of evicted entries.
+func (c *Client) Resize(capacity int) int {
+	var result resizeResult
+	_ = c.getJSON(http.MethodPost, c.baseURL+"/resize?cap="+strconv.Itoa(capacity), &result)
+
+	return result.Evicted
+}
+
+// Clear removes all data on the server.
+func (c *Client) Clear() {
+	c.status(http.MethodPost, c.baseURL+"/clear")
+}
+
+// Stats returns statistics of the cache on the server.
+func (c *Client) Stats() Stats {
+	stats, _ := c.ServerStats()
+
+	return stats.Stats
+}
+
+// ServerStats returns statistics and capacity of the cache on the server.
+func (c *Client) ServerStats() (ServerStats, error) {
+	var stats ServerStats
+	err := c.getJSON(http.MethodGet, c.baseURL+"/stats", &stats)
+
+	return stats, err
+}
+
+func (c *Client) keyURL(key string) string {
+	return c.baseURL + keysPath + url.PathEscape(key)
+}
+
+func (c *Client) get(u string) ([]byte, bool) {
+	resp, err := c.do(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false
+	}
+
+	value, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
+
+func (c *Client) getJSON(method, u string, v interface{}) error {
+	resp, err := c.do(method, u, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return unexpectedResponse(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnexpectedResponse, err)
+	}
+
+	return nil
+}
+
+// status sends request and returns status code of the response, or zero if request failed.
+func (c *Client) status(method, u string) int {
+	resp, err := c.do(method, u, nil)
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func (c *Client) do(method, u string, body []byte) (*http.Response, error) {
+	// methods of Cache interface take no context, requests are limited by timeout of the client
+	req, err := http.NewRequestWithContext(context.Background(), method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.httpClient.Do(req)
+}
+
+func readMessage(resp *http.Response) string {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	return strings.TrimSpace(string(message))
+}
+
+func unexpectedResponse(resp *http.Response) error {
+	return fmt.Errorf("%w: %s: %s", ErrUnexpectedResponse, resp.Status, readMessage(resp))
+}
@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, c Cache[string, []byte]) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(NewHandler(c))
+	t.Cleanup(server.Close)
+
+	return NewClient(server.URL, server.Client())
+}
+
+func mustSetBytes(t *testing.T, c Cache[string, []byte], key, value string) bool {
+	t.Helper()
+	existed, err := c.Set(key, []byte(value))
+	require.NoError(t, err)
+
+	return existed
+}
+
+func TestClientSetAndGet(t *testing.T) {
+	c := newTestServer(t, New[string, []byte](2))
+
+	assert.False(t, mustSetBytes(t, c, "testKey", "testValue"))
+	assert.True(t, mustSetBytes(t, c, "testKey", "newTestValue"))
+
+	value, ok := c.Get("testKey")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, []byte("newTestValue"))
+
+	_, ok = c.Get("nonexistentKey")
+
+	assert.False(t, ok)
+}
+
+func TestClientEscapesKeys(t *testing.T) {
+	local := New[string, []byte](5)
+	c := newTestServer(t, local)
+
+	for _, key := range []string{"a/b", "with space", "query?x=1", "percent%2F"} {
+		mustSetBytes(t, c, key, key)
+
+		value, ok := c.Get(key)
+
+		assert.True(t, ok)
+		assert.Equal(t, value, []byte(key))
+		assert.True(t, local.Contains(key))
+	}
+}
+
+func TestClientEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newTestServer(t, New[string, []byte](2))
+
+	mustSetBytes(t, c, "testKey1", "testValue1")
+	mustSetBytes(t, c, "testKey2", "testValue2")
+	c.Get("testKey1")
+	mustSetBytes(t, c, "testKey3", "testValue3")
+
+	assert.Equal(t, c.Keys(), []string{"testKey3", "testKey1"})
+	assert.Equal(t, c.Len(), 2)
+	assert.Equal(t, c.Cap(), 2)
+}
+
+func TestClientPeekAndContainsDoNotPromote(t *testing.T) {
+	c := newTestServer(t, New[string, []byte](2))
+
+	mustSetBytes(t, c, "testKey1", "testValue1")
+	mustSetBytes(t, c, "testKey2", "testValue2")
+
+	value, ok := c.Peek("testKey1")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, []byte("testValue1"))
+	assert.True(t, c.Contains("testKey1"))
+	assert.False(t, c.Contains("nonexistentKey"))
+	assert.Equal(t, c.Keys(), []string{"testKey2", "testKey1"})
+}
+
+func TestClientDeleteAndClear(t *testing.T) {
+	c := newTestServer(t, New[string, []byte](5))
+
+	mustSetBytes(t, c, "testKey1", "testValue1")
+	mustSetBytes(t, c, "testKey2", "testValue2")
+
+	assert.True(t, c.Delete("testKey1"))
+	assert.False(t, c.Delete("testKey1"))
+
+	c.Clear()
+
+	assert.Equal(t, c.Len(), 0)
+	assert.Empty(t, c.Keys())
+}
+
+func TestClientResize(t *testing.T) {
+	c := newTestServer(t, New[string, []byte](3))
+
+	mustSetBytes(t, c, "testKey1", "testValue1")
+	mustSetBytes(t, c, "testKey2", "testValue2")
+	mustSetBytes(t, c, "testKey3", "testValue3")
+
+	assert.Equal(t, c.Resize(1), 2)
+	assert.Equal(t, c.Cap(), 1)
+	assert.Equal(t, c.Keys(), []string{"testKey3"})
+
+	// zero capacity empties the cache instead of removing the limit
+	assert.Equal(t, c.Resize(0), 1)
+	assert.Equal(t, c.Len(), 0)
+	assert.Equal(t, c.Cap(), 0)
+}
+
+func TestClientStats(t *testing.T) {
+	c := newTestServer(t, New[string, []byte](5))
+
+	mustSetBytes(t, c, "testKey", "testValue")
+	c.Get("testKey")
+	c.Get("nonexistentKey")
+
+	stats := c.Stats()
+
+	assert.Equal(t, stats.Len, 1)
+	assert.Equal(t, stats.Sets, uint64(1))
+	assert.Equal(t, stats.Hits, uint64(1))
+	assert.Equal(t, stats.Misses, uint64(1))
+}
+
+func TestClientTooLarge(t *testing.T) {
+	sizer := func(value []byte) int64 { return int64(len(value)) }
+	c := newTestServer(t, New[string, []byte](5, WithMaxCost(4), WithSizer(sizer)))
+
+	_, err := c.Set("testKey", []byte("testValue"))
+
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestClientBodyTooLarge(t *testing.T) {
+	server := httptest.NewServer(NewHandler(New[string, []byte](5), WithMaxBodySize(4)))
+	defer server.Close()
+	c := NewClient(server.URL, server.Client())
+
+	_, err := c.Set("testKey", []byte("testValue"))
+
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestClientServerUnavailable(t *testing.T) {
+	server := httptest.NewServer(NewHandler(New[string, []byte](5)))
+	c := NewClient(server.URL, server.Client())
+	server.Close()
+
+	_, err := c.Set("testKey", []byte("testValue"))
+
+	assert.Error(t, err)
+
+	_, ok := c.Get("testKey")
+
+	assert.False(t, ok)
+	assert.False(t, c.Contains("testKey"))
+	assert.Equal(t, c.Len(), 0)
+	assert.Empty(t, c.Keys())
+}
+
+func TestClientTimeout(t *testing.T) {
+	assert.Equal(t, NewClient("http://localhost", nil).httpClient.Timeout, DefaultClientTimeout)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	c := NewClient(server.URL, &http.Client{Timeout: 50 * time.Millisecond})
+
+	// hanging server looks like a cache which lost its contents
+	_, ok := c.Get("testKey")
+
+	assert.False(t, ok)
+
+	_, err := c.Set("testKey", []byte("testValue"))
+
+	assert.Error(t, err)
+}
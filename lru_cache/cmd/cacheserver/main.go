@@ -0,0 +1,81 @@
+// Command cacheserver hosts LRU cache shared by several processes over HTTP.
+// See cache.Handler for description of the protocol and cache.Client for Go client.
+// With -memcached-addr it also serves separate cache over memcached text protocol.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	cache "github.com/tamirok/go-learn/lru_cache"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	capacity := flag.Int("capacity", 10000, "maximum number of entries, zero means unlimited")
+	maxBytes := flag.Int64("max-bytes", 0, "maximum total size of values in bytes, zero means unlimited")
+	ttl := flag.Duration("ttl", 0, "time to live of entries, zero means entries never expire")
+	memcachedAddr := flag.String("memcached-addr", "", "address to serve memcached text protocol on, disabled if empty")
+	flag.Parse()
+
+	if *capacity <= 0 {
+		*capacity = cache.Unlimited
+	}
+
+	opts := []cache.Option{
+		cache.WithDefaultTTL(*ttl),
+		cache.WithMaxCost(*maxBytes),
+		cache.WithSizer(func(value []byte) int64 { return int64(len(value)) }),
+	}
+	if *ttl > 0 {
+		opts = append(opts, cache.WithJanitor(*ttl))
+	}
+
+	c := cache.New[string, []byte](*capacity, opts...)
+	defer c.Stop()
+
+	exporter := cache.NewMetricsExporter("cacheserver")
+	exporter.Register("default", c)
+
+	mux := http.NewServeMux()
+	// values larger than the whole budget are rejected before they are read
+	mux.Handle("/", cache.NewHandler(c, cache.WithMaxBodySize(*maxBytes)))
+	mux.Handle("/metrics", exporter)
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 2)
+
+	if *memcachedAddr != "" {
+		sizer := func(item cache.MemcachedItem) int64 { return int64(len(item.Data)) }
+		items := cache.New[string, cache.MemcachedItem](*capacity, cache.WithMaxCost(*maxBytes), cache.WithSizer(sizer))
+		exporter.Register("memcached", items)
+
+		memcached := cache.NewMemcachedServer(items)
+		defer memcached.Close()
+
+		go func() {
+			log.Printf("memcached server is listening on %s", *memcachedAddr)
+			errs <- memcached.ListenAndServe(*memcachedAddr)
+		}()
+	}
+
+	go func() {
+		log.Printf("cache server is listening on %s", *addr)
+		errs <- server.ListenAndServe()
+	}()
+
+	return <-errs
+}
@@ -0,0 +1,548 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrCorruptedStore = errors.New("corrupted disk store")
+
+// errCompactionObsolete means the store was cleared while compaction copied records.
+var errCompactionObsolete = errors.New("store was cleared during compaction")
+
+const (
+	// recordHeaderSize is size of big endian length prefix of every record
+	recordHeaderSize      = 4
+	defaultCompactGarbage = 1 << 20
+)
+
+// diskRecord is a serialized form of an entry stored on disk.
+// Deleted records are tombstones, which hide previous records of the key.
+type diskRecord[K comparable, V any] struct {
+	Key       K
+	Value     V
+	ExpiresAt time.Time
+	Deleted   bool
+}
+
+// diskEntry points to the latest record of a key in the file.
+type diskEntry struct {
+	offset    int64
+	size      int64
+	expiresAt time.Time
+	// seq orders entries by the moment they were written
+	seq uint64
+}
+
+func (e diskEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// diskStore keeps entries in append-only file with in-memory index of live records.
+// Updates append new records and deletes append tombstones, so file grows with stale records
+// until it is compacted. Compaction runs in background once stale records take more space
+// than live ones and more than configured threshold, it holds the mutex only to start
+// and to swap files. It is safe for concurrent use.
+type diskStore[K comparable, V any] struct {
+	mu sync.Mutex
+	// compactMu serializes compactions, which run mostly without holding mu
+	compactMu      sync.Mutex
+	path           string
+	file           *os.File
+	codec          Codec
+	clock          Clock
+	index          map[K]diskEntry
+	seq            uint64
+	size           int64
+	live           int64
+	clears         uint64
+	compactGarbage int64
+	compact        chan struct{}
+	done           chan struct{}
+	wg             sync.WaitGroup
+	closed         bool
+}
+
+// openDiskStore opens file with given path creating it if needed and builds index of its records.
+// Incomplete record at the end of file, e.g. left by a crash, is truncated.
+func openDiskStore[K comparable, V any](path string, o options) (*diskStore[K, V], error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &diskStore[K, V]{
+		path:           path,
+		file:           file,
+		codec:          o.codec,
+		clock:          o.clock,
+		index:          make(map[K]diskEntry),
+		compactGarbage: o.compactGarbage,
+		compact:        make(chan struct{}, 1),
+		done:           make(chan struct{}),
+	}
+	if s.codec == nil {
+		s.codec = GobCodec{}
+	}
+	if s.compactGarbage <= 0 {
+		s.compactGarbage = defaultCompactGarbage
+	}
+
+	if err := s.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go s.runCompaction()
+
+	return s, nil
+}
+
+// load reads all records of the file into index.
+func (s *diskStore[K, V]) load() error {
+	r := bufio.NewReader(s.file)
+	header := make([]byte, recordHeaderSize)
+	now := s.clock.Now()
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return s.file.Truncate(s.size)
+			}
+
+			return err
+		}
+
+		payload := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := io.ReadFull(r, payload); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return s.file.Truncate(s.size)
+			}
+
+			return err
+		}
+
+		var record diskRecord[K, V]
+		if err := s.codec.NewDecoder(bytes.NewReader(payload)).Decode(&record); err != nil {
+			return fmt.Errorf("%w: record at offset %d: %w", ErrCorruptedStore, s.size, err)
+		}
+
+		size := int64(recordHeaderSize + len(payload))
+		if record.Deleted {
+			s.unindex(record.Key)
+		} else {
+			s.indexRecord(record.Key, s.size, size, record.ExpiresAt)
+			if s.index[record.Key].expired(now) {
+				s.unindex(record.Key)
+			}
+		}
+		s.size += size
+	}
+}
+
+// put appends record with given key and value to the file.
+func (s *diskStore[K, V]) put(key K, value V, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offset, size, err := s.write(diskRecord[K, V]{Key: key, Value: value, ExpiresAt: expiresAt})
+	if err != nil {
+		return err
+	}
+
+	s.indexRecord(key, offset, size, expiresAt)
+	s.maybeCompact()
+
+	return nil
+}
+
+// get reads value with given key and its expiration time from the file.
+// Expired entries are treated as missing.
+func (s *diskStore[K, V]) get(key K) (V, time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var zero V
+	e, ok := s.index[key]
+	if !ok {
+		return zero, time.Time{}, false, nil
+	}
+
+	if e.expired(s.clock.Now()) {
+		// latest record of the key is expired, so it is skipped on load without tombstone
+		s.unindex(key)
+		return zero, time.Time{}, false, nil
+	}
+
+	record, err := s.read(e)
+	if err != nil {
+		return zero, time.Time{}, false, err
+	}
+
+	return record.Value, record.ExpiresAt, true, nil
+}
+
+// contains reports whether not expired entry with given key is stored.
+func (s *diskStore[K, V]) contains(key K) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.index[key]
+
+	return ok && !e.expired(s.clock.Now())
+}
+
+// delete appends tombstone of given key to the file.
+// It returns boolean indicating existence of not expired entry with the key.
+func (s *diskStore[K, V]) delete(key K) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.index[key]
+	if !ok {
+		return false, nil
+	}
+
+	if _, _, err := s.write(diskRecord[K, V]{Key: key, Deleted: true}); err != nil {
+		return false, err
+	}
+
+	s.unindex(key)
+	s.maybeCompact()
+
+	return !e.expired(s.clock.Now()), nil
+}
+
+// len returns number of stored entries. Expired entries which are not removed yet are counted too.
+func (s *diskStore[K, V]) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.index)
+}
+
+// keys returns keys of not expired entries from the most to the least recently written.
+func (s *diskStore[K, V]) keys() []K {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	keys := make([]K, 0, len(s.index))
+	for key, e := range s.index {
+		if !e.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return s.index[keys[i]].seq > s.index[keys[j]].seq
+	})
+
+	return keys
+}
+
+// clear removes all entries truncating the file.
+func (s *diskStore[K, V]) clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.index = make(map[K]diskEntry)
+	s.size = 0
+	s.live = 0
+	s.clears++
+
+	return s.file.Truncate(0)
+}
+
+// compaction is a rewrite of the file started at some moment.
+type compaction[K comparable] struct {
+	// file is the file being compacted, size is its size at the start
+	file *os.File
+	size int64
+	// clears is number of clears at the start, clear during compaction makes it obsolete
+	clears uint64
+	// keys and entries of live records ordered by offset
+	keys    []K
+	entries []diskEntry
+	tmpPath string
+}
+
+// compactNow rewrites the file keeping only the latest records of not expired entries.
+// Records are copied without holding the mutex, so the store is not blocked meanwhile.
+func (s *diskStore[K, V]) compactNow() error {
+	s.compactMu.Lock()
+	defer s.compactMu.Unlock()
+
+	c, err := s.startCompaction()
+	if err != nil {
+		return err
+	}
+
+	offsets, err := c.rewrite()
+	if err != nil {
+		os.Remove(c.tmpPath)
+		return err
+	}
+
+	if err := s.finishCompaction(c, offsets); err != nil {
+		os.Remove(c.tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+// startCompaction drops expired entries and remembers live records to copy.
+func (s *diskStore[K, V]) startCompaction() (*compaction[K], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, os.ErrClosed
+	}
+
+	now := s.clock.Now()
+	keys := make([]K, 0, len(s.index))
+	for key, e := range s.index {
+		if e.expired(now) {
+			s.unindex(key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	// keep records in the order they were written
+	sort.Slice(keys, func(i, j int) bool {
+		return s.index[keys[i]].offset < s.index[keys[j]].offset
+	})
+
+	entries := make([]diskEntry, len(keys))
+	for i, key := range keys {
+		entries[i] = s.index[key]
+	}
+
+	return &compaction[K]{
+		file:    s.file,
+		size:    s.size,
+		clears:  s.clears,
+		keys:    keys,
+		entries: entries,
+		tmpPath: s.path + ".compact",
+	}, nil
+}
+
+// rewrite copies remembered records to the temporary file and returns their offsets in it.
+// Records are not modified once written, so they are read without holding the mutex.
+func (c *compaction[K]) rewrite() ([]int64, error) {
+	file, err := os.Create(c.tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	offsets := make([]int64, 0, len(c.entries))
+	var offset int64
+
+	for _, e := range c.entries {
+		buf := make([]byte, e.size)
+		if _, err := c.file.ReadAt(buf, e.offset); err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(buf); err != nil {
+			return nil, err
+		}
+
+		offsets = append(offsets, offset)
+		offset += e.size
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return offsets, file.Sync()
+}
+
+// finishCompaction appends records written during compaction to the temporary file,
+// replaces the file with it and points index to new offsets.
+func (s *diskStore[K, V]) finishCompaction(c *compaction[K], offsets []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return os.ErrClosed
+	}
+	if s.clears != c.clears {
+		return errCompactionObsolete
+	}
+
+	// records written meanwhile are appended as is, they are few and stale ones are dropped next time
+	copied := int64(0)
+	if len(offsets) > 0 {
+		copied = offsets[len(offsets)-1] + c.entries[len(c.entries)-1].size
+	}
+	if err := appendFileRange(c.tmpPath, c.file, c.size, s.size-c.size); err != nil {
+		return err
+	}
+
+	if err := os.Rename(c.tmpPath, s.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	s.file.Close()
+	s.file = file
+
+	for i, key := range c.keys {
+		// entries rewritten meanwhile point to the appended records
+		if e, ok := s.index[key]; ok && e.seq == c.entries[i].seq {
+			e.offset = offsets[i]
+			s.index[key] = e
+		}
+	}
+	for key, e := range s.index {
+		if e.offset >= c.size {
+			e.offset = e.offset - c.size + copied
+			s.index[key] = e
+		}
+	}
+	s.size = s.size - c.size + copied
+
+	return nil
+}
+
+// appendFileRange appends n bytes of src starting at offset to the file with given path.
+func appendFileRange(path string, src *os.File, offset, n int64) error {
+	if n == 0 {
+		return nil
+	}
+
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, io.NewSectionReader(src, offset, n)); err != nil {
+		dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
+
+// close stops background compaction and closes the file.
+func (s *diskStore[K, V]) close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	return s.file.Close()
+}
+
+func (s *diskStore[K, V]) runCompaction() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.compact:
+			// failed compaction is retried after the next write
+			_ = s.compactNow()
+		}
+	}
+}
+
+// maybeCompact requests background compaction if stale records take too much space.
+// It must be called with mutex held.
+func (s *diskStore[K, V]) maybeCompact() {
+	garbage := s.size - s.live
+	if garbage <= s.compactGarbage || garbage <= s.live {
+		return
+	}
+
+	select {
+	case s.compact <- struct{}{}:
+	default:
+	}
+}
+
+// write appends given record to the end of file and returns its offset and size.
+// It must be called with mutex held.
+func (s *diskStore[K, V]) write(record diskRecord[K, V]) (int64, int64, error) {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, recordHeaderSize))
+	if err := s.codec.NewEncoder(&buf).Encode(record); err != nil {
+		return 0, 0, err
+	}
+
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data, uint32(len(data)-recordHeaderSize))
+
+	offset := s.size
+	if _, err := s.file.WriteAt(data, offset); err != nil {
+		return 0, 0, err
+	}
+	s.size += int64(len(data))
+
+	return offset, int64(len(data)), nil
+}
+
+// read decodes record pointed by given index entry.
+// It must be called with mutex held.
+func (s *diskStore[K, V]) read(e diskEntry) (diskRecord[K, V], error) {
+	var record diskRecord[K, V]
+
+	buf := make([]byte, e.size)
+	if _, err := s.file.ReadAt(buf, e.offset); err != nil {
+		return record, err
+	}
+
+	if err := s.codec.NewDecoder(bytes.NewReader(buf[recordHeaderSize:])).Decode(&record); err != nil {
+		return record, fmt.Errorf("%w: record at offset %d: %w", ErrCorruptedStore, e.offset, err)
+	}
+
+	return record, nil
+}
+
+// indexRecord points key to the record with given offset and size, replacing previous one.
+// It must be called with mutex held.
+func (s *diskStore[K, V]) indexRecord(key K, offset, size int64, expiresAt time.Time) {
+	s.unindex(key)
+
+	s.seq++
+	s.index[key] = diskEntry{offset: offset, size: size, expiresAt: expiresAt, seq: s.seq}
+	s.live += size
+}
+
+// unindex forgets the latest record of given key, so it becomes stale.
+// It must be called with mutex held.
+func (s *diskStore[K, V]) unindex(key K) {
+	if e, ok := s.index[key]; ok {
+		s.live -= e.size
+		delete(s.index, key)
+	}
+}
@@ -0,0 +1,46 @@
+package cache
+
+import "time"
+
+// EvictReason describes why an entry left the cache.
+type EvictReason int
+
+const (
+	// EvictCapacity means entry was dropped to make room for a new one.
+	EvictCapacity EvictReason = iota
+	// EvictDeleted means entry was removed explicitly with Delete.
+	EvictDeleted
+	// EvictCleared means entry was removed by Clear.
+	EvictCleared
+	// EvictExpired means entry outlived its time to live.
+	EvictExpired
+	// EvictReplaced means entry value was overwritten by Set. It is reported only with WithNotifyReplaced.
+	EvictReplaced
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictDeleted:
+		return "deleted"
+	case EvictCleared:
+		return "cleared"
+	case EvictExpired:
+		return "expired"
+	case EvictReplaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictFunc is called for every entry which leaves the cache.
+type EvictFunc[K comparable, V any] func(key K, value V, reason EvictReason)
+
+type eviction[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+	reason    EvictReason
+}
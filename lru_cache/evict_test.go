@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type evictedEntry struct {
+	key    Key
+	value  interface{}
+	reason EvictReason
+}
+
+func recordEvictions(c *LRUCache[Key, interface{}]) *[]evictedEntry {
+	var evicted []evictedEntry
+
+	c.OnEvict(func(key Key, value interface{}, reason EvictReason) {
+		evicted = append(evicted, evictedEntry{key: key, value: value, reason: reason})
+	})
+
+	return &evicted
+}
+
+func TestEvictReasonString(t *testing.T) {
+	assert.Equal(t, EvictCapacity.String(), "capacity")
+	assert.Equal(t, EvictDeleted.String(), "deleted")
+	assert.Equal(t, EvictCleared.String(), "cleared")
+	assert.Equal(t, EvictExpired.String(), "expired")
+	assert.Equal(t, EvictReplaced.String(), "replaced")
+	assert.Equal(t, EvictReason(100).String(), "unknown")
+}
+
+func TestOnEvictCapacity(t *testing.T) {
+	c := NewLRUCache(2)
+	evicted := recordEvictions(c)
+
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Set("testKey3", "testValue3")
+
+	assert.Equal(t, *evicted, []evictedEntry{{"testKey1", "testValue1", EvictCapacity}})
+}
+
+func TestOnEvictReplaced(t *testing.T) {
+	c := NewLRUCache(2, WithNotifyReplaced(true))
+	evicted := recordEvictions(c)
+
+	c.Set("testKey", "testValue")
+	c.Set("testKey", "newTestValue")
+
+	assert.Equal(t, *evicted, []evictedEntry{{"testKey", "testValue", EvictReplaced}})
+}
+
+func TestOnEvictReplacedIsNotReportedByDefault(t *testing.T) {
+	c := NewLRUCache(2)
+	evicted := recordEvictions(c)
+
+	// the same value stored again must not be closed by the callback
+	c.Set("testKey", "testValue")
+	c.Set("testKey", "testValue")
+	c.Set("testKey", "newTestValue")
+
+	assert.Empty(t, *evicted)
+}
+
+func TestOnEvictDeleted(t *testing.T) {
+	c := NewLRUCache(2)
+	evicted := recordEvictions(c)
+
+	c.Set("testKey", "testValue")
+
+	assert.True(t, c.Delete("testKey"))
+	assert.False(t, c.Delete("testKey"))
+	assertKeyDoesNotExist(t, c, "testKey")
+	assert.Equal(t, *evicted, []evictedEntry{{"testKey", "testValue", EvictDeleted}})
+}
+
+func TestOnEvictCleared(t *testing.T) {
+	c := NewLRUCache(2)
+	evicted := recordEvictions(c)
+
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Clear()
+
+	assert.Equal(t, *evicted, []evictedEntry{
+		{"testKey2", "testValue2", EvictCleared},
+		{"testKey1", "testValue1", EvictCleared},
+	})
+}
+
+func TestOnEvictExpired(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(5, WithClock(clock))
+	evicted := recordEvictions(c)
+
+	c.SetWithTTL("testKey1", "testValue1", time.Minute)
+	c.SetWithTTL("testKey2", "testValue2", time.Minute)
+	clock.Advance(time.Minute)
+
+	c.Get("testKey1")
+	c.DeleteExpired()
+
+	assert.Equal(t, *evicted, []evictedEntry{
+		{"testKey1", "testValue1", EvictExpired},
+		{"testKey2", "testValue2", EvictExpired},
+	})
+}
+
+func TestOnEvictCanUseCache(t *testing.T) {
+	c := NewLRUCache(2)
+
+	c.OnEvict(func(key Key, value interface{}, reason EvictReason) {
+		// would deadlock if callback was called with lock held
+		if key == "testKey1" {
+			c.Set("evicted", key)
+		}
+	})
+
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Set("testKey3", "testValue3")
+
+	value, ok := c.Get("evicted")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, Key("testKey1"))
+}
+
+func TestShardedLRUCacheOnEvict(t *testing.T) {
+	c := NewShardedLRUCache(10, 4)
+	mu := sync.Mutex{}
+	var keys []Key
+
+	c.OnEvict(func(key Key, value interface{}, reason EvictReason) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, key)
+	})
+
+	c.Set("testKey", "testValue")
+	c.Delete("testKey")
+
+	assert.Equal(t, keys, []Key{"testKey"})
+}
@@ -0,0 +1,308 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Operations of fuzz targets are encoded as pairs of bytes: operation and its argument.
+const (
+	listOpPushFront = iota
+	listOpPushBack
+	listOpRemove
+	listOpRemoveStale
+	listOpMoveToFront
+	listOpMoveToBack
+	listOpInit
+	listOpCount
+)
+
+const (
+	cacheOpSet = iota
+	cacheOpGet
+	cacheOpPeek
+	cacheOpDelete
+	cacheOpClear
+	cacheOpResize
+	cacheOpCount
+)
+
+// fuzzKeys limits number of distinct keys, so operations hit existing entries often.
+const fuzzKeys = 16
+
+// checkListInvariants verifies links of the list and returns its items from front to back.
+func checkListInvariants(t *testing.T, list *DoublyLinkedList[int]) []*ListItem[int] {
+	t.Helper()
+
+	if list.Len() == 0 {
+		require.Nil(t, list.Front())
+		require.Nil(t, list.Back())
+
+		return nil
+	}
+
+	require.NotNil(t, list.Front())
+	require.NotNil(t, list.Back())
+	require.Nil(t, list.Front().Prev)
+	require.Nil(t, list.Back().Next)
+
+	forward := make([]*ListItem[int], 0, list.Len())
+	for item := list.Front(); item != nil; item = item.Next {
+		// broken links could make the walk endless
+		require.Less(t, len(forward), list.Len(), "forward walk is longer than length")
+		require.True(t, list.contains(item))
+		if item.Next != nil {
+			require.Same(t, item.Next.Prev, item)
+		}
+		forward = append(forward, item)
+	}
+	require.Len(t, forward, list.Len())
+
+	i := len(forward) - 1
+	for item := list.Back(); item != nil; item = item.Prev {
+		require.GreaterOrEqual(t, i, 0, "backward walk is longer than length")
+		require.Same(t, item, forward[i])
+		i--
+	}
+	require.Equal(t, i, -1, "backward walk is shorter than length")
+
+	return forward
+}
+
+// checkArenaInvariants verifies links and free slots of the list and returns its indices from front to back.
+func checkArenaInvariants[T any](t *testing.T, list *arenaList[T]) []int {
+	t.Helper()
+
+	if list.Len() == 0 {
+		require.Zero(t, list.Front())
+		require.Zero(t, list.Back())
+	}
+
+	forward := make([]int, 0, list.Len())
+	for i := list.Front(); i != 0; i = list.Next(i) {
+		require.Less(t, len(forward), list.Len(), "forward walk is longer than length")
+		require.Equal(t, list.Next(list.Prev(i)), i)
+		forward = append(forward, i)
+	}
+	require.Len(t, forward, list.Len())
+
+	j := len(forward) - 1
+	for i := list.Back(); i != 0; i = list.Prev(i) {
+		require.GreaterOrEqual(t, j, 0, "backward walk is longer than length")
+		require.Equal(t, i, forward[j])
+		j--
+	}
+	require.Equal(t, j, -1, "backward walk is shorter than length")
+
+	free := 0
+	for i := list.free; i != 0; i = list.nodes[i].next {
+		require.Less(t, free, len(list.nodes), "free list is cyclic")
+		free++
+	}
+	if len(list.nodes) > 0 {
+		// every slot except root is either used or free
+		require.Equal(t, free+list.Len(), len(list.nodes)-1)
+	}
+
+	return forward
+}
+
+func FuzzDoublyLinkedList(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{listOpPushFront, 1, listOpPushBack, 2, listOpPushFront, 3, listOpRemove, 1})
+	f.Add([]byte{listOpPushBack, 1, listOpPushBack, 2, listOpMoveToFront, 1, listOpMoveToBack, 0, listOpRemoveStale, 0})
+	f.Add([]byte{listOpPushBack, 1, listOpInit, 0, listOpRemoveStale, 0, listOpPushFront, 2, listOpRemove, 0})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		list := DoublyLinkedList[int]{}
+		// model keeps items in expected order, removed keeps items which left the list
+		var model, removed []*ListItem[int]
+
+		for i := 0; i+1 < len(ops); i += 2 {
+			op, arg := ops[i]%listOpCount, int(ops[i+1])
+
+			switch op {
+			case listOpPushFront:
+				model = append([]*ListItem[int]{list.PushFront(arg)}, model...)
+			case listOpPushBack:
+				model = append(model, list.PushBack(arg))
+			case listOpRemove:
+				if len(model) == 0 {
+					continue
+				}
+				j := arg % len(model)
+				list.Remove(model[j])
+				removed = append(removed, model[j])
+				model = append(model[:j:j], model[j+1:]...)
+			case listOpRemoveStale:
+				if len(removed) == 0 {
+					continue
+				}
+				// items which are not in the list are ignored
+				list.Remove(removed[arg%len(removed)])
+			case listOpMoveToFront:
+				if len(model) == 0 {
+					continue
+				}
+				j := arg % len(model)
+				list.MoveToFront(model[j])
+				item := model[j]
+				model = append([]*ListItem[int]{item}, append(model[:j:j], model[j+1:]...)...)
+			case listOpMoveToBack:
+				if len(model) == 0 {
+					continue
+				}
+				j := arg % len(model)
+				list.MoveToBack(model[j])
+				item := model[j]
+				model = append(append(model[:j:j], model[j+1:]...), item)
+			case listOpInit:
+				list.Init()
+				removed = append(removed, model...)
+				model = nil
+			}
+
+			items := checkListInvariants(t, &list)
+			require.Len(t, items, len(model))
+			for j := range model {
+				require.Same(t, items[j], model[j], "item %d after operation %d", j, i/2)
+			}
+		}
+	})
+}
+
+// lruModel is a trivially correct LRU cache keeping keys from the most to the least recently used.
+type lruModel struct {
+	capacity int
+	keys     []int
+	values   map[int]int
+}
+
+// touch moves key to the front of recency order, adding it if missing.
+func (m *lruModel) touch(key int) {
+	m.unlink(key)
+	m.keys = append([]int{key}, m.keys...)
+}
+
+func (m *lruModel) remove(key int) bool {
+	delete(m.values, key)
+
+	return m.unlink(key)
+}
+
+// unlink removes key from recency order keeping its value.
+func (m *lruModel) unlink(key int) bool {
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i:i], m.keys[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// set stores value of key, evicting the least recently used key first if a new key does not fit.
+// Model of zero capacity stores nothing.
+func (m *lruModel) set(key int, value int) {
+	if _, ok := m.values[key]; !ok {
+		if m.capacity == 0 {
+			return
+		}
+		if len(m.keys) >= m.capacity {
+			m.remove(m.keys[len(m.keys)-1])
+		}
+	}
+
+	m.touch(key)
+	m.values[key] = value
+}
+
+func (m *lruModel) shrink() {
+	for len(m.keys) > m.capacity {
+		m.remove(m.keys[len(m.keys)-1])
+	}
+}
+
+// fuzzCapacity maps fuzzed byte to capacity, covering zero, small and unlimited capacities.
+func fuzzCapacity(b byte) int {
+	if b%9 == 8 {
+		return Unlimited
+	}
+
+	return int(b % 9)
+}
+
+func FuzzLRUCache(f *testing.F) {
+	f.Add(uint8(2), []byte{})
+	f.Add(uint8(2), []byte{cacheOpSet, 1, cacheOpSet, 2, cacheOpGet, 1, cacheOpSet, 3, cacheOpGet, 2})
+	f.Add(uint8(3), []byte{cacheOpSet, 1, cacheOpDelete, 1, cacheOpSet, 2, cacheOpClear, 0, cacheOpSet, 1})
+	f.Add(uint8(0), []byte{cacheOpSet, 1, cacheOpSet, 2, cacheOpResize, 1, cacheOpPeek, 1, cacheOpResize, 0})
+	f.Add(uint8(8), []byte{cacheOpSet, 1, cacheOpSet, 2, cacheOpSet, 3, cacheOpResize, 2, cacheOpResize, 8, cacheOpSet, 4})
+
+	f.Fuzz(func(t *testing.T, capacity uint8, ops []byte) {
+		c := New[int, int](fuzzCapacity(capacity))
+		m := &lruModel{capacity: c.Cap(), values: make(map[int]int)}
+
+		for i := 0; i+1 < len(ops); i += 2 {
+			op, key, value := ops[i]%cacheOpCount, int(ops[i+1])%fuzzKeys, i
+
+			switch op {
+			case cacheOpSet:
+				_, existed := m.values[key]
+				m.set(key, value)
+
+				got, err := c.Set(key, value)
+				require.NoError(t, err)
+				require.Equal(t, got, existed, "set %d", key)
+			case cacheOpGet:
+				expected, ok := m.values[key]
+				if ok {
+					m.touch(key)
+				}
+
+				got, gotOK := c.Get(key)
+				require.Equal(t, gotOK, ok, "get %d", key)
+				require.Equal(t, got, expected, "get %d", key)
+			case cacheOpPeek:
+				expected, ok := m.values[key]
+
+				got, gotOK := c.Peek(key)
+				require.Equal(t, gotOK, ok, "peek %d", key)
+				require.Equal(t, got, expected, "peek %d", key)
+			case cacheOpDelete:
+				require.Equal(t, c.Delete(key), m.remove(key), "delete %d", key)
+			case cacheOpClear:
+				c.Clear()
+				m.keys = nil
+				m.values = make(map[int]int)
+			case cacheOpResize:
+				// resize argument is a capacity, not a key
+				m.capacity = fuzzCapacity(ops[i+1])
+				m.shrink()
+				c.Resize(m.capacity)
+			}
+
+			checkCacheInvariants(t, c)
+			require.Equal(t, c.Keys(), append([]int{}, m.keys...), "keys after operation %d", i/2)
+		}
+	})
+}
+
+// checkCacheInvariants verifies that queue and storage of the cache describe the same entries.
+func checkCacheInvariants(t *testing.T, c *LRUCache[int, int]) {
+	t.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	indices := checkArenaInvariants(t, c.queue)
+	require.Len(t, c.storage, len(indices))
+	for _, i := range indices {
+		key := c.queue.Value(i).key
+		require.Equal(t, c.storage[key], i, "storage of key %d", key)
+	}
+
+	require.LessOrEqual(t, len(indices), c.capacity)
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+const defaultVirtualNodes = 50
+
+// HashRing assigns keys to nodes with consistent hashing. Every node is placed on the ring
+// several times as virtual nodes, so keys are spread evenly and adding or removing a node
+// moves only keys of that node. It is not safe for concurrent modification.
+type HashRing struct {
+	hash         func(data []byte) uint32
+	virtualNodes int
+	// hashes are sorted positions of virtual nodes on the ring
+	hashes []uint32
+	nodes  map[uint32]string
+}
+
+// NewHashRing returns pointer to newly created HashRing placing every node given number of times.
+// Non-positive number of virtual nodes means default one. If hash is nil, crc32.ChecksumIEEE is used.
+func NewHashRing(virtualNodes int, hash func(data []byte) uint32) *HashRing {
+	if virtualNodes <= 0 {
+		virtualNodes = defaultVirtualNodes
+	}
+	if hash == nil {
+		hash = crc32.ChecksumIEEE
+	}
+
+	return &HashRing{
+		hash:         hash,
+		virtualNodes: virtualNodes,
+		nodes:        make(map[uint32]string),
+	}
+}
+
+// Add places given nodes on the ring.
+func (r *HashRing) Add(nodes ...string) {
+	for _, node := range nodes {
+		for i := 0; i < r.virtualNodes; i++ {
+			h := r.hash([]byte(strconv.Itoa(i) + node))
+			if _, ok := r.nodes[h]; !ok {
+				r.hashes = append(r.hashes, h)
+			}
+			r.nodes[h] = node
+		}
+	}
+
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+}
+
+// Remove removes given node from the ring.
+func (r *HashRing) Remove(node string) {
+	hashes := r.hashes[:0]
+	for _, h := range r.hashes {
+		if r.nodes[h] == node {
+			delete(r.nodes, h)
+			continue
+		}
+		hashes = append(hashes, h)
+	}
+
+	r.hashes = hashes
+}
+
+// Get returns node owning given key, or empty string if the ring is empty.
+func (r *HashRing) Get(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+
+	h := r.hash([]byte(key))
+	// the first virtual node clockwise from the key owns it
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+
+	return r.nodes[r.hashes[i]]
+}
+
+// Nodes returns sorted list of nodes on the ring.
+func (r *HashRing) Nodes() []string {
+	seen := make(map[string]struct{})
+	nodes := make([]string, 0)
+	for _, node := range r.nodes {
+		if _, ok := seen[node]; !ok {
+			seen[node] = struct{}{}
+			nodes = append(nodes, node)
+		}
+	}
+
+	sort.Strings(nodes)
+
+	return nodes
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashRingEmpty(t *testing.T) {
+	r := NewHashRing(3, nil)
+
+	assert.Equal(t, r.Get("testKey"), "")
+	assert.Empty(t, r.Nodes())
+}
+
+func TestHashRingGet(t *testing.T) {
+	// positions of virtual nodes and keys are their numeric values
+	r := NewHashRing(3, func(data []byte) uint32 {
+		h, err := strconv.Atoi(string(data))
+		if err != nil {
+			panic(err)
+		}
+
+		return uint32(h)
+	})
+
+	// virtual nodes 2, 12, 22 and 4, 14, 24 and 6, 16, 26
+	r.Add("6", "4", "2")
+
+	for key, node := range map[string]string{
+		"2": "2", "11": "2", "23": "4", "27": "2", "15": "6",
+	} {
+		assert.Equal(t, r.Get(key), node, key)
+	}
+
+	r.Add("8")
+
+	assert.Equal(t, r.Get("27"), "8")
+
+	r.Remove("8")
+
+	assert.Equal(t, r.Get("27"), "2")
+	assert.Equal(t, r.Nodes(), []string{"2", "4", "6"})
+}
+
+func TestHashRingMovesFewKeys(t *testing.T) {
+	r := NewHashRing(0, nil)
+	r.Add("node1", "node2", "node3")
+
+	owners := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		key := "testKey" + strconv.Itoa(i)
+		owners[key] = r.Get(key)
+		counts[owners[key]]++
+	}
+
+	// virtual nodes spread keys evenly enough
+	for node, count := range counts {
+		assert.Greater(t, count, 500, node)
+	}
+
+	r.Add("node4")
+
+	moved := 0
+	for key, owner := range owners {
+		if newOwner := r.Get(key); newOwner != owner {
+			// keys move to the new node only
+			assert.Equal(t, newOwner, "node4")
+			moved++
+		}
+	}
+
+	assert.Greater(t, moved, 0)
+	assert.Less(t, moved, 1500)
+}
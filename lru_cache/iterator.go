@@ -0,0 +1,146 @@
+package cache
+
+import "errors"
+
+var ErrConcurrentModification = errors.New("list was modified during iteration")
+
+// Seq is an iterator over single values.
+// It has the same shape as iter.Seq, so it can be used with range-over-func loops.
+type Seq[T any] func(yield func(T) bool)
+
+// Seq2 is an iterator over pairs of values.
+// It has the same shape as iter.Seq2, so it can be used with range-over-func loops.
+type Seq2[K, V any] func(yield func(K, V) bool)
+
+// ListIterator walks doubly linked list in one direction.
+// Items may be removed during iteration only with Remove method of the iterator,
+// any other modification of the list stops iteration with ErrConcurrentModification.
+type ListIterator[T any] struct {
+	list     *DoublyLinkedList[T]
+	current  *ListItem[T]
+	next     *ListItem[T]
+	backward bool
+	version  uint64
+	err      error
+}
+
+// Iterator returns iterator walking the list from front to back.
+func (l *DoublyLinkedList[T]) Iterator() *ListIterator[T] {
+	return &ListIterator[T]{list: l, next: l.front, version: l.version}
+}
+
+// ReverseIterator returns iterator walking the list from back to front.
+func (l *DoublyLinkedList[T]) ReverseIterator() *ListIterator[T] {
+	return &ListIterator[T]{list: l, next: l.back, backward: true, version: l.version}
+}
+
+// Next advances iterator to the next item.
+// It returns false when there are no more items or the list was modified.
+func (it *ListIterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.version != it.list.version {
+		it.err = ErrConcurrentModification
+		it.current = nil
+
+		return false
+	}
+
+	it.current = it.next
+	if it.current == nil {
+		return false
+	}
+
+	if it.backward {
+		it.next = it.current.Prev
+	} else {
+		it.next = it.current.Next
+	}
+
+	return true
+}
+
+// Item returns current item of the iterator.
+func (it *ListIterator[T]) Item() *ListItem[T] {
+	return it.current
+}
+
+// Value returns value of current item of the iterator.
+func (it *ListIterator[T]) Value() T {
+	return it.current.Value
+}
+
+// Remove removes current item from the list, iteration continues with the next item.
+func (it *ListIterator[T]) Remove() {
+	if it.current == nil || it.err != nil {
+		return
+	}
+
+	it.list.Remove(it.current)
+	it.current = nil
+	it.version = it.list.version
+}
+
+// Err returns ErrConcurrentModification if the list was modified during iteration.
+func (it *ListIterator[T]) Err() error {
+	return it.err
+}
+
+// All returns iterator over values of the list from front to back.
+// It panics with ErrConcurrentModification if the list is modified during iteration.
+func (l *DoublyLinkedList[T]) All() Seq[T] {
+	return func(yield func(T) bool) {
+		it := l.Iterator()
+		for it.Next() {
+			if !yield(it.Value()) {
+				return
+			}
+		}
+
+		if it.Err() != nil {
+			panic(it.Err())
+		}
+	}
+}
+
+// Backward returns iterator over values of the list from back to front.
+// It panics with ErrConcurrentModification if the list is modified during iteration.
+func (l *DoublyLinkedList[T]) Backward() Seq[T] {
+	return func(yield func(T) bool) {
+		it := l.ReverseIterator()
+		for it.Next() {
+			if !yield(it.Value()) {
+				return
+			}
+		}
+
+		if it.Err() != nil {
+			panic(it.Err())
+		}
+	}
+}
+
+// All returns iterator over keys and values of not expired entries
+// from the most to the least recently used. Iteration does not update recency of entries.
+// Entries are collected when iteration starts, so the cache may be used during iteration.
+func (c *LRUCache[K, V]) All() Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c.mu.Lock()
+		now := c.clock.Now()
+		entries := make([]entry[K, V], 0, c.queue.Len())
+		for i := c.queue.Front(); i != 0; i = c.queue.Next(i) {
+			if item := c.queue.Value(i); !item.expired(now) {
+				entries = append(entries, entry[K, V]{key: item.key, value: item.value})
+			}
+		}
+		c.mu.Unlock()
+
+		for _, e := range entries {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestList(values ...int) *DoublyLinkedList[int] {
+	list := &DoublyLinkedList[int]{}
+	for _, v := range values {
+		list.PushBack(v)
+	}
+
+	return list
+}
+
+func TestListIterator(t *testing.T) {
+	list := newTestList(1, 2, 3)
+	var values []int
+
+	for it := list.Iterator(); it.Next(); {
+		values = append(values, it.Value())
+	}
+
+	assert.Equal(t, values, []int{1, 2, 3})
+}
+
+func TestListReverseIterator(t *testing.T) {
+	list := newTestList(1, 2, 3)
+	var values []int
+
+	it := list.ReverseIterator()
+	for it.Next() {
+		values = append(values, it.Item().Value)
+	}
+
+	assert.Equal(t, values, []int{3, 2, 1})
+	assert.NoError(t, it.Err())
+}
+
+func TestListIteratorEmptyList(t *testing.T) {
+	it := newTestList().Iterator()
+
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
+
+func TestListIteratorRemove(t *testing.T) {
+	list := newTestList(1, 2, 3, 4)
+
+	it := list.Iterator()
+	for it.Next() {
+		if it.Value()%2 == 0 {
+			it.Remove()
+		}
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, list.Len(), 2)
+	assert.Equal(t, list.Front().Value, 1)
+	assert.Equal(t, list.Back().Value, 3)
+}
+
+func TestListIteratorDetectsModification(t *testing.T) {
+	list := newTestList(1, 2, 3)
+
+	it := list.Iterator()
+	it.Next()
+	list.PushBack(4)
+
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Err(), ErrConcurrentModification)
+	assert.False(t, it.Next())
+}
+
+func TestListAll(t *testing.T) {
+	list := newTestList(1, 2, 3)
+	var values []int
+
+	list.All()(func(v int) bool {
+		values = append(values, v)
+		return true
+	})
+
+	assert.Equal(t, values, []int{1, 2, 3})
+}
+
+func TestListAllStopsEarly(t *testing.T) {
+	list := newTestList(1, 2, 3)
+	var values []int
+
+	list.All()(func(v int) bool {
+		values = append(values, v)
+		return v < 2
+	})
+
+	assert.Equal(t, values, []int{1, 2})
+}
+
+func TestListBackward(t *testing.T) {
+	list := newTestList(1, 2, 3)
+	var values []int
+
+	list.Backward()(func(v int) bool {
+		values = append(values, v)
+		return true
+	})
+
+	assert.Equal(t, values, []int{3, 2, 1})
+}
+
+func TestListAllPanicsOnModification(t *testing.T) {
+	list := newTestList(1, 2, 3)
+
+	assert.PanicsWithError(t, ErrConcurrentModification.Error(), func() {
+		list.All()(func(v int) bool {
+			list.Remove(list.Back())
+			return true
+		})
+	})
+}
+
+func TestLRUCacheAll(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(5, WithClock(clock))
+	c.Set("testKey1", "testValue1")
+	c.SetWithTTL("testKey2", "testValue2", time.Minute)
+	c.Set("testKey3", "testValue3")
+	c.Get("testKey1")
+	clock.Advance(time.Minute)
+
+	var keys []Key
+	var values []interface{}
+
+	c.All()(func(key Key, value interface{}) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+		// cache can be used during iteration
+		c.Peek(key)
+		return true
+	})
+
+	assert.Equal(t, keys, []Key{"testKey1", "testKey3"})
+	assert.Equal(t, values, []interface{}{"testValue1", "testValue3"})
+	// iteration does not promote entries
+	assertQueueFrontValue(t, c, "testValue1")
+}
+
+func TestLRUCacheAllStopsEarly(t *testing.T) {
+	c := NewLRUCache(5)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+
+	calls := 0
+	c.All()(func(key Key, value interface{}) bool {
+		calls++
+		return false
+	})
+
+	assert.Equal(t, calls, 1)
+}
@@ -0,0 +1,246 @@
+package cache
+
+import (
+	"sync"
+)
+
+var _ Cache[Key, interface{}] = (*LFUCache[Key, interface{}])(nil)
+
+type lfuItem[K comparable, V any] struct {
+	key    K
+	value  V
+	bucket *ListItem[*lfuBucket[K, V]]
+}
+
+// lfuBucket holds entries used given number of times, the most recently used entry is in front.
+type lfuBucket[K comparable, V any] struct {
+	freq  int
+	items *DoublyLinkedList[*lfuItem[K, V]]
+}
+
+// LFUCache is an implementation of a cache with the least frequently used policy.
+// Entries with equal frequency are evicted in the least recently used order.
+// All operations take constant time. It implements Cache interface and is safe for concurrent use.
+// Capacity Unlimited means number of entries is not limited, cache of zero capacity stores nothing.
+type LFUCache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	storage  map[K]*ListItem[*lfuItem[K, V]]
+	// buckets are ordered by frequency, so the least frequently used entries are in the front one.
+	// Empty buckets are removed.
+	buckets  DoublyLinkedList[*lfuBucket[K, V]]
+	counters counters
+}
+
+// NewLFU returns pointer to newly created LFUCache with given capacity.
+func NewLFU[K comparable, V any](capacity int) *LFUCache[K, V] {
+	return &LFUCache[K, V]{
+		capacity: max(capacity, 0),
+		storage:  make(map[K]*ListItem[*lfuItem[K, V]], preallocated(capacity)),
+	}
+}
+
+// Set stores key with given value. Updating existing key counts as its use.
+// Sets return boolean indicating existence of a given key in the cache.
+func (c *LFUCache[K, V]) Set(key K, value V) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counters.sets.Add(1)
+
+	if listItem, ok := c.storage[key]; ok {
+		listItem.Value.value = value
+		c.touch(listItem)
+
+		return true, nil
+	}
+
+	// cache of zero capacity keeps nothing
+	if c.capacity == 0 {
+		c.counters.evictions.Add(1)
+		return false, nil
+	}
+
+	if len(c.storage) >= c.capacity {
+		c.evict()
+	}
+
+	front := c.buckets.Front()
+	if front == nil || front.Value.freq != 1 {
+		front = c.buckets.PushFront(&lfuBucket[K, V]{freq: 1, items: &DoublyLinkedList[*lfuItem[K, V]]{}})
+	}
+
+	item := &lfuItem[K, V]{key: key, value: value, bucket: front}
+	c.storage[key] = front.Value.items.PushFront(item)
+
+	return false, nil
+}
+
+// Get returns value associated with a given key in the cache and
+// boolean indicating existence of key in the cache.
+func (c *LFUCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	listItem, ok := c.storage[key]
+	if !ok {
+		c.counters.misses.Add(1)
+		var zero V
+		return zero, false
+	}
+
+	c.counters.hits.Add(1)
+	c.touch(listItem)
+
+	return listItem.Value.value, true
+}
+
+// Peek returns value associated with a given key in the cache and
+// boolean indicating existence of key in the cache without counting it as use.
+func (c *LFUCache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	listItem, ok := c.storage[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	return listItem.Value.value, true
+}
+
+// Contains reports whether given key exists in the cache without counting it as use.
+func (c *LFUCache[K, V]) Contains(key K) bool {
+	_, ok := c.Peek(key)
+
+	return ok
+}
+
+// Delete removes given key from the cache.
+// Delete returns boolean indicating existence of a given key in the cache.
+func (c *LFUCache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	listItem, ok := c.storage[key]
+	if !ok {
+		return false
+	}
+
+	c.remove(listItem)
+
+	return true
+}
+
+// Len returns number of entries in the cache.
+func (c *LFUCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.storage)
+}
+
+// Cap returns capacity of the cache.
+func (c *LFUCache[K, V]) Cap() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.capacity
+}
+
+// Keys returns keys from the most to the least frequently used.
+// Keys with equal frequency are ordered from the most to the least recently used.
+func (c *LFUCache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]K, 0, len(c.storage))
+	for bucket := c.buckets.Back(); bucket != nil; bucket = bucket.Prev {
+		for item := bucket.Value.items.Front(); item != nil; item = item.Next {
+			keys = append(keys, item.Value.key)
+		}
+	}
+
+	return keys
+}
+
+// Resize changes capacity of the cache, evicting the least frequently used entries
+// which do not fit into new capacity. Resize returns number of evicted entries.
+func (c *LFUCache[K, V]) Resize(capacity int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capacity = max(capacity, 0)
+
+	evicted := 0
+	for len(c.storage) > c.capacity {
+		c.evict()
+		evicted++
+	}
+
+	return evicted
+}
+
+// Clear removes all data in the cache.
+func (c *LFUCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.storage = make(map[K]*ListItem[*lfuItem[K, V]], preallocated(c.capacity))
+	c.buckets.Init()
+}
+
+// Stats returns snapshot of the cache state.
+func (c *LFUCache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{Len: len(c.storage)}
+	c.counters.fill(&stats)
+
+	return stats
+}
+
+// touch moves given item to the bucket of the next frequency, creating it if needed.
+func (c *LFUCache[K, V]) touch(listItem *ListItem[*lfuItem[K, V]]) {
+	item := listItem.Value
+	current := item.bucket
+
+	next := current.Next
+	if next == nil || next.Value.freq != current.Value.freq+1 {
+		next = c.buckets.InsertAfter(
+			&lfuBucket[K, V]{freq: current.Value.freq + 1, items: &DoublyLinkedList[*lfuItem[K, V]]{}}, current,
+		)
+	}
+
+	c.unlink(listItem)
+	next.Value.items.pushFront(listItem)
+	item.bucket = next
+}
+
+// unlink removes given item from its bucket, dropping the bucket if it becomes empty.
+func (c *LFUCache[K, V]) unlink(listItem *ListItem[*lfuItem[K, V]]) {
+	bucket := listItem.Value.bucket
+	bucket.Value.items.Remove(listItem)
+
+	if bucket.Value.items.Len() == 0 {
+		c.buckets.Remove(bucket)
+	}
+}
+
+func (c *LFUCache[K, V]) remove(listItem *ListItem[*lfuItem[K, V]]) {
+	c.unlink(listItem)
+	delete(c.storage, listItem.Value.key)
+}
+
+// evict removes the least recently used entry among the least frequently used ones.
+func (c *LFUCache[K, V]) evict() {
+	front := c.buckets.Front()
+	if front == nil {
+		return
+	}
+
+	c.remove(front.Value.items.Back())
+	c.counters.evictions.Add(1)
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLFUCacheEvictsLeastFrequentlyUsed(t *testing.T) {
+	c := NewLFU[Key, string](2)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Get("testKey1")
+	c.Get("testKey1")
+	c.Get("testKey2")
+
+	c.Set("testKey3", "testValue3")
+
+	assert.False(t, c.Contains("testKey2"))
+	assert.True(t, c.Contains("testKey1"))
+	assert.True(t, c.Contains("testKey3"))
+}
+
+func TestLFUCacheEvictsLeastRecentlyUsedAmongEqualFrequency(t *testing.T) {
+	c := NewLFU[Key, string](2)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+
+	c.Set("testKey3", "testValue3")
+
+	assert.Equal(t, c.Keys(), []Key{"testKey3", "testKey2"})
+}
+
+func TestLFUCacheKeysOrder(t *testing.T) {
+	c := NewLFU[Key, string](5)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Set("testKey3", "testValue3")
+	c.Get("testKey2")
+	c.Get("testKey2")
+	c.Get("testKey1")
+	// peek does not count as use
+	c.Peek("testKey3")
+
+	assert.Equal(t, c.Keys(), []Key{"testKey2", "testKey1", "testKey3"})
+}
+
+func TestLFUCacheEvictAfterDeleteOfLeastFrequent(t *testing.T) {
+	c := NewLFU[Key, string](2)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Get("testKey2")
+	c.Get("testKey2")
+	c.Get("testKey1")
+	// minimal frequency list becomes empty
+	c.Delete("testKey1")
+
+	c.Set("testKey3", "testValue3")
+	c.Get("testKey3")
+	c.Set("testKey4", "testValue4")
+
+	assert.Equal(t, c.Keys(), []Key{"testKey2", "testKey4"})
+}
+
+func bucketFrequencies[K comparable, V any](c *LFUCache[K, V]) []int {
+	var freqs []int
+	for bucket := c.buckets.Front(); bucket != nil; bucket = bucket.Next {
+		freqs = append(freqs, bucket.Value.freq)
+	}
+
+	return freqs
+}
+
+func TestLFUCacheBucketsStayOrderedAfterDelete(t *testing.T) {
+	c := NewLFU[Key, string](5)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Set("testKey3", "testValue3")
+	c.Get("testKey2")
+	c.Get("testKey3")
+	c.Get("testKey3")
+
+	assert.Equal(t, bucketFrequencies(c), []int{1, 2, 3})
+
+	// empty buckets are dropped, so the front one always has the minimal frequency
+	c.Delete("testKey1")
+	c.Delete("testKey2")
+
+	assert.Equal(t, bucketFrequencies(c), []int{3})
+
+	c.Set("testKey4", "testValue4")
+	c.Get("testKey4")
+
+	assert.Equal(t, bucketFrequencies(c), []int{2, 3})
+
+	c.Clear()
+
+	assert.Empty(t, bucketFrequencies(c))
+}
+
+func TestLFUCacheStats(t *testing.T) {
+	c := NewLFU[Key, string](1)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Get("testKey1")
+	c.Get("testKey2")
+
+	stats := c.Stats()
+
+	assert.Equal(t, stats.Len, 1)
+	assert.Equal(t, stats.Sets, uint64(2))
+	assert.Equal(t, stats.Hits, uint64(1))
+	assert.Equal(t, stats.Misses, uint64(1))
+	assert.Equal(t, stats.Evictions, uint64(1))
+}
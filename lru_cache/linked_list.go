@@ -1,49 +1,91 @@
 package cache
 
 // ListItem represents single item of a list.
-type ListItem struct {
-	Value interface{}
-	Next  *ListItem
-	Prev  *ListItem
+type ListItem[T any] struct {
+	Value T
+	Next  *ListItem[T]
+	Prev  *ListItem[T]
+	// identity of list item belongs to, nil if item was removed
+	list *listID
 }
 
-type List interface {
+// listID identifies list contents, it is replaced on Init,
+// so items removed by Init are not treated as members of the list anymore.
+type listID struct {
+	// non-zero size guarantees distinct addresses of identities
+	_ byte
+}
+
+// AnyListItem is ListItem with value of any type, as it was before it became generic.
+type AnyListItem = ListItem[interface{}]
+
+type List[T any] interface {
+	Init() *DoublyLinkedList[T]
 	Len() int
-	Front() *ListItem
-	Back() *ListItem
-	PushFront(v interface{}) *ListItem
-	PushBack(v interface{}) *ListItem
-	Remove(i *ListItem)
-	MoveToFront(i *ListItem)
+	Front() *ListItem[T]
+	Back() *ListItem[T]
+	PushFront(v T) *ListItem[T]
+	PushBack(v T) *ListItem[T]
+	InsertBefore(v T, mark *ListItem[T]) *ListItem[T]
+	InsertAfter(v T, mark *ListItem[T]) *ListItem[T]
+	PushFrontList(other *DoublyLinkedList[T])
+	PushBackList(other *DoublyLinkedList[T])
+	Remove(i *ListItem[T])
+	MoveToFront(i *ListItem[T])
+	MoveToBack(i *ListItem[T])
+	MoveBefore(i, mark *ListItem[T])
+	MoveAfter(i, mark *ListItem[T])
 }
 
+var _ List[int] = (*DoublyLinkedList[int])(nil)
+
 // DoublyLinkedList is the implementation of doulby linked list data structure.
-// It implements List interface.
-type DoublyLinkedList struct {
+// It implements List interface. Zero value is an empty list ready to use.
+//
+// Operations taking items are no-op if an item does not belong to the list,
+// e.g. it was already removed or it belongs to another list.
+type DoublyLinkedList[T any] struct {
 	len   int
-	front *ListItem
-	back  *ListItem
+	front *ListItem[T]
+	back  *ListItem[T]
+	// version changes on every modification, it is used to detect modification during iteration
+	version uint64
+	id      *listID
+}
+
+// AnyList is DoublyLinkedList with values of any type, as it was before it became generic.
+type AnyList = DoublyLinkedList[interface{}]
+
+// Init removes all items from the doubly linked list.
+func (l *DoublyLinkedList[T]) Init() *DoublyLinkedList[T] {
+	l.len = 0
+	l.front = nil
+	l.back = nil
+	l.id = nil
+	l.version++
+
+	return l
 }
 
 // Len returns a length of the doubly linked list.
-func (l *DoublyLinkedList) Len() int {
+func (l *DoublyLinkedList[T]) Len() int {
 	return l.len
 }
 
 // Front returns first element of the doubly linked list.
-func (l *DoublyLinkedList) Front() *ListItem {
+func (l *DoublyLinkedList[T]) Front() *ListItem[T] {
 	return l.front
 }
 
 // Back returns last element of the doubly linked list.
-func (l *DoublyLinkedList) Back() *ListItem {
+func (l *DoublyLinkedList[T]) Back() *ListItem[T] {
 	return l.back
 }
 
 // PushFront puts given value to the beginning of the doubly linked list.
 // it can be very fast and sometimes it is very slow.
-func (l *DoublyLinkedList) PushFront(v interface{}) *ListItem {
-	newFront := &ListItem{
+func (l *DoublyLinkedList[T]) PushFront(v T) *ListItem[T] {
+	newFront := &ListItem[T]{
 		Value: v,
 	}
 	l.pushFront(newFront)
@@ -51,46 +93,147 @@ func (l *DoublyLinkedList) PushFront(v interface{}) *ListItem {
 	return newFront
 }
 
-func (l *DoublyLinkedList) pushFront(newFront *ListItem) {
-	newFront.Next = l.front
-	newFront.Prev = nil
+func (l *DoublyLinkedList[T]) pushFront(newFront *ListItem[T]) {
+	l.insert(newFront, nil)
+}
 
-	if l.front != nil {
-		l.front.Prev = newFront
+// PushFront puts given value to the end of the doubly linked list.
+func (l *DoublyLinkedList[T]) PushBack(v T) *ListItem[T] {
+	newBack := &ListItem[T]{
+		Value: v,
 	}
-	l.front = newFront
+	l.insert(newBack, l.back)
 
-	// create tail if list is empty
-	if l.back == nil {
-		l.back = newFront
-	}
+	return newBack
+}
 
-	l.len++
+// contains reports whether given item belongs to the list.
+func (l *DoublyLinkedList[T]) contains(i *ListItem[T]) bool {
+	return i.list != nil && i.list == l.id
 }
 
-// PushFront puts given value to the end of the doubly linked list.
-func (l *DoublyLinkedList) PushBack(v interface{}) *ListItem {
-	newBack := &ListItem{
+// InsertBefore puts given value right before mark item and returns new item.
+// It returns nil if mark does not belong to the list.
+func (l *DoublyLinkedList[T]) InsertBefore(v T, mark *ListItem[T]) *ListItem[T] {
+	if !l.contains(mark) {
+		return nil
+	}
+
+	newItem := &ListItem[T]{
 		Value: v,
-		Prev:  l.back,
 	}
-	if l.back != nil {
-		l.back.Next = newBack
+	l.insert(newItem, mark.Prev)
+
+	return newItem
+}
+
+// InsertAfter puts given value right after mark item and returns new item.
+// It returns nil if mark does not belong to the list.
+func (l *DoublyLinkedList[T]) InsertAfter(v T, mark *ListItem[T]) *ListItem[T] {
+	if !l.contains(mark) {
+		return nil
 	}
-	l.back = newBack
 
-	// create head if list is empty
-	if l.front == nil {
-		l.front = newBack
+	newItem := &ListItem[T]{
+		Value: v,
 	}
+	l.insert(newItem, mark)
 
-	l.len++
+	return newItem
+}
 
-	return newBack
+// PushFrontList puts copies of values of other list to the beginning of the doubly linked list
+// keeping their order. Other list may be the same list.
+func (l *DoublyLinkedList[T]) PushFrontList(other *DoublyLinkedList[T]) {
+	// walk by length, so pushing list to itself does not loop forever
+	for i, item := other.Len(), other.Back(); i > 0; i, item = i-1, item.Prev {
+		l.PushFront(item.Value)
+	}
+}
+
+// PushBackList puts copies of values of other list to the end of the doubly linked list
+// keeping their order. Other list may be the same list.
+func (l *DoublyLinkedList[T]) PushBackList(other *DoublyLinkedList[T]) {
+	// walk by length, so pushing list to itself does not loop forever
+	for i, item := other.Len(), other.Front(); i > 0; i, item = i-1, item.Next {
+		l.PushBack(item.Value)
+	}
 }
 
 // Remove removes given item from the doubly linked list.
-func (l *DoublyLinkedList) Remove(i *ListItem) {
+func (l *DoublyLinkedList[T]) Remove(i *ListItem[T]) {
+	if !l.contains(i) {
+		return
+	}
+
+	l.unlink(i)
+}
+
+// MoveToFront moves given item to the beginnin of the doubly linked list.
+func (l *DoublyLinkedList[T]) MoveToFront(i *ListItem[T]) {
+	if !l.contains(i) || l.front == i {
+		return
+	}
+	l.unlink(i)
+	l.pushFront(i)
+}
+
+// MoveToBack moves given item to the end of the doubly linked list.
+func (l *DoublyLinkedList[T]) MoveToBack(i *ListItem[T]) {
+	if !l.contains(i) || l.back == i {
+		return
+	}
+	l.unlink(i)
+	l.insert(i, l.back)
+}
+
+// MoveBefore moves given item right before mark item.
+func (l *DoublyLinkedList[T]) MoveBefore(i, mark *ListItem[T]) {
+	if !l.contains(i) || !l.contains(mark) || i == mark || i.Next == mark {
+		return
+	}
+	l.unlink(i)
+	l.insert(i, mark.Prev)
+}
+
+// MoveAfter moves given item right after mark item.
+func (l *DoublyLinkedList[T]) MoveAfter(i, mark *ListItem[T]) {
+	if !l.contains(i) || !l.contains(mark) || i == mark || i.Prev == mark {
+		return
+	}
+	l.unlink(i)
+	l.insert(i, mark)
+}
+
+// insert links given item right after prev item, or to the beginning if prev is nil.
+func (l *DoublyLinkedList[T]) insert(i, prev *ListItem[T]) {
+	i.Prev = prev
+
+	if prev == nil {
+		i.Next = l.front
+		l.front = i
+	} else {
+		i.Next = prev.Next
+		prev.Next = i
+	}
+
+	if i.Next != nil {
+		i.Next.Prev = i
+	} else {
+		l.back = i
+	}
+
+	if l.id == nil {
+		l.id = &listID{}
+	}
+
+	i.list = l.id
+	l.len++
+	l.version++
+}
+
+// unlink removes given item, which must belong to the list.
+func (l *DoublyLinkedList[T]) unlink(i *ListItem[T]) {
 	if i.Next != nil {
 		i.Next.Prev = i.Prev
 	} else {
@@ -103,14 +246,11 @@ func (l *DoublyLinkedList) Remove(i *ListItem) {
 		l.front = i.Next
 	}
 
-	l.len--
-}
+	// drop links, so removed item does not keep other items alive
+	i.Next = nil
+	i.Prev = nil
+	i.list = nil
 
-// MoveToFront moves given item to the beginnin of the doubly linked list.
-func (l *DoublyLinkedList) MoveToFront(i *ListItem) {
-	if l.front == i {
-		return
-	}
-	l.Remove(i)
-	l.pushFront(i)
+	l.len--
+	l.version++
 }
@@ -7,7 +7,7 @@ import (
 )
 
 func TestDoublyLinkedListLen(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	assert.Equal(t, list.Len(), 0)
 
@@ -19,7 +19,7 @@ func TestDoublyLinkedListLen(t *testing.T) {
 }
 
 func TestDoublyLinkedListFront(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	assert.Nil(t, list.Front())
 
@@ -35,7 +35,7 @@ func TestDoublyLinkedListFront(t *testing.T) {
 }
 
 func TestDoublyLinkedListBack(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	assert.Nil(t, list.Back())
 
@@ -51,7 +51,7 @@ func TestDoublyLinkedListBack(t *testing.T) {
 }
 
 func TestDoublyLinkedListPushFront(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	for i := 0; i < 5; i++ {
 		list.PushFront(i)
@@ -74,7 +74,7 @@ func TestDoublyLinkedListPushFront(t *testing.T) {
 }
 
 func TestDoublyLinkedListPushBack(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	for i := 0; i < 5; i++ {
 		list.PushBack(i)
@@ -97,7 +97,7 @@ func TestDoublyLinkedListPushBack(t *testing.T) {
 }
 
 func TestDoublyLinkedListRemove(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	one := list.PushBack(1)
 	two := list.PushFront(2)
@@ -117,7 +117,7 @@ func TestDoublyLinkedListRemove(t *testing.T) {
 }
 
 func TestDoublyLinkedListRemoveFront(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	one := list.PushBack(1)
 	two := list.PushFront(2)
@@ -134,7 +134,7 @@ func TestDoublyLinkedListRemoveFront(t *testing.T) {
 }
 
 func TestDoublyLinkedListRemoveBack(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	one := list.PushBack(1)
 	list.PushFront(2)
@@ -151,7 +151,7 @@ func TestDoublyLinkedListRemoveBack(t *testing.T) {
 }
 
 func TestDoublyLinkedListRemoveSingleItem(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	one := list.PushBack(1)
 	list.Remove(one)
@@ -162,7 +162,7 @@ func TestDoublyLinkedListRemoveSingleItem(t *testing.T) {
 }
 
 func TestDoublyLinkedListMoveToFront(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	one := list.PushBack(1)
 	list.PushFront(2)
@@ -177,7 +177,7 @@ func TestDoublyLinkedListMoveToFront(t *testing.T) {
 }
 
 func TestDoublyLinkedListMoveToFrontFrontItem(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	list.PushBack(1)
 	two := list.PushFront(2)
@@ -192,7 +192,7 @@ func TestDoublyLinkedListMoveToFrontFrontItem(t *testing.T) {
 }
 
 func TestDoublyLinkedListMoveToFrontBackItem(t *testing.T) {
-	list := DoublyLinkedList{}
+	list := DoublyLinkedList[int]{}
 
 	one := list.PushBack(1)
 	list.PushFront(2)
@@ -206,3 +206,227 @@ func TestDoublyLinkedListMoveToFrontBackItem(t *testing.T) {
 	assert.Equal(t, list.Front(), three)
 	assert.Equal(t, list.Back(), one)
 }
+
+func listValues(list *DoublyLinkedList[int]) []int {
+	values := make([]int, 0, list.Len())
+	for item := list.Front(); item != nil; item = item.Next {
+		values = append(values, item.Value)
+	}
+
+	return values
+}
+
+func listValuesBackward(list *DoublyLinkedList[int]) []int {
+	values := make([]int, 0, list.Len())
+	for item := list.Back(); item != nil; item = item.Prev {
+		values = append(values, item.Value)
+	}
+
+	return values
+}
+
+func assertListValues(t *testing.T, list *DoublyLinkedList[int], expected ...int) {
+	t.Helper()
+	// copy, so expected values are never nil and can be reversed in place
+	expected = append([]int{}, expected...)
+
+	assert.Equal(t, list.Len(), len(expected))
+	assert.Equal(t, listValues(list), expected)
+
+	for i, j := 0, len(expected)-1; i < j; i, j = i+1, j-1 {
+		expected[i], expected[j] = expected[j], expected[i]
+	}
+
+	assert.Equal(t, listValuesBackward(list), expected)
+}
+
+func TestDoublyLinkedListInit(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	one := list.PushBack(1)
+	list.PushBack(2)
+
+	assert.Same(t, list.Init(), &list)
+	assertListValues(t, &list)
+
+	list.PushBack(3)
+
+	assertListValues(t, &list, 3)
+
+	// items removed by Init do not belong to the list anymore
+	list.Remove(one)
+	list.MoveToFront(one)
+
+	assertListValues(t, &list, 3)
+}
+
+func TestDoublyLinkedListInsertBefore(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	one := list.PushBack(1)
+	three := list.PushBack(3)
+
+	two := list.InsertBefore(2, three)
+	zero := list.InsertBefore(0, one)
+
+	assert.Equal(t, two.Value, 2)
+	assert.Equal(t, list.Front(), zero)
+	assertListValues(t, &list, 0, 1, 2, 3)
+}
+
+func TestDoublyLinkedListInsertAfter(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	one := list.PushBack(1)
+	three := list.PushBack(3)
+
+	list.InsertAfter(2, one)
+	four := list.InsertAfter(4, three)
+
+	assert.Equal(t, list.Back(), four)
+	assertListValues(t, &list, 1, 2, 3, 4)
+}
+
+func TestDoublyLinkedListInsertWithForeignMark(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	other := DoublyLinkedList[int]{}
+	mark := other.PushBack(1)
+
+	assert.Nil(t, list.InsertBefore(2, mark))
+	assert.Nil(t, list.InsertAfter(2, mark))
+	assertListValues(t, &list)
+	assertListValues(t, &other, 1)
+}
+
+func TestDoublyLinkedListMoveToBack(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	one := list.PushBack(1)
+	list.PushBack(2)
+	three := list.PushBack(3)
+
+	list.MoveToBack(one)
+
+	assertListValues(t, &list, 2, 3, 1)
+
+	list.MoveToBack(one)
+
+	assertListValues(t, &list, 2, 3, 1)
+
+	list.MoveToBack(three)
+
+	assertListValues(t, &list, 2, 1, 3)
+}
+
+func TestDoublyLinkedListMoveBefore(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	one := list.PushBack(1)
+	two := list.PushBack(2)
+	three := list.PushBack(3)
+
+	list.MoveBefore(three, one)
+
+	assertListValues(t, &list, 3, 1, 2)
+
+	list.MoveBefore(three, two)
+
+	assertListValues(t, &list, 1, 3, 2)
+
+	// item is already before mark
+	list.MoveBefore(one, three)
+	list.MoveBefore(two, two)
+
+	assertListValues(t, &list, 1, 3, 2)
+}
+
+func TestDoublyLinkedListMoveAfter(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	one := list.PushBack(1)
+	two := list.PushBack(2)
+	three := list.PushBack(3)
+
+	list.MoveAfter(one, three)
+
+	assertListValues(t, &list, 2, 3, 1)
+
+	list.MoveAfter(one, two)
+
+	assertListValues(t, &list, 2, 1, 3)
+
+	// item is already after mark
+	list.MoveAfter(one, two)
+	list.MoveAfter(three, three)
+
+	assertListValues(t, &list, 2, 1, 3)
+}
+
+func TestDoublyLinkedListPushBackList(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	list.PushBack(1)
+	other := DoublyLinkedList[int]{}
+	other.PushBack(2)
+	other.PushBack(3)
+
+	list.PushBackList(&other)
+
+	assertListValues(t, &list, 1, 2, 3)
+	assertListValues(t, &other, 2, 3)
+
+	list.PushBackList(&list)
+
+	assertListValues(t, &list, 1, 2, 3, 1, 2, 3)
+}
+
+func TestDoublyLinkedListPushFrontList(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	list.PushBack(3)
+	other := DoublyLinkedList[int]{}
+	other.PushBack(1)
+	other.PushBack(2)
+
+	list.PushFrontList(&other)
+
+	assertListValues(t, &list, 1, 2, 3)
+
+	list.PushFrontList(&list)
+
+	assertListValues(t, &list, 1, 2, 3, 1, 2, 3)
+}
+
+func TestDoublyLinkedListRemoveTwice(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	list.PushBack(1)
+	two := list.PushBack(2)
+	list.PushBack(3)
+
+	list.Remove(two)
+	list.Remove(two)
+
+	assertListValues(t, &list, 1, 3)
+	assert.Nil(t, two.Next)
+	assert.Nil(t, two.Prev)
+}
+
+func TestDoublyLinkedListForeignItemIsIgnored(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	one := list.PushBack(1)
+	list.PushBack(2)
+	other := DoublyLinkedList[int]{}
+	foreign := other.PushBack(3)
+
+	list.Remove(foreign)
+	list.MoveToFront(foreign)
+	list.MoveToBack(foreign)
+	list.MoveBefore(foreign, one)
+	list.MoveAfter(one, foreign)
+
+	assertListValues(t, &list, 1, 2)
+	assertListValues(t, &other, 3)
+}
+
+func TestDoublyLinkedListMoveRemovedItemIsIgnored(t *testing.T) {
+	list := DoublyLinkedList[int]{}
+	one := list.PushBack(1)
+	list.PushBack(2)
+	list.Remove(one)
+
+	list.MoveToFront(one)
+
+	assertListValues(t, &list, 2)
+}
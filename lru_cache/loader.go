@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// LoaderFunc loads value which is missing in the cache.
+type LoaderFunc[V any] func(ctx context.Context) (V, error)
+
+// LoaderPanicError is a panic of loader, which GetOrLoad panics with in every caller waiting for the load.
+type LoaderPanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is stack trace of the loader goroutine at the moment of the panic.
+	Stack []byte
+}
+
+func (e *LoaderPanicError) Error() string {
+	return fmt.Sprintf("cache: loader panicked: %v\n\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the value passed to panic if it is an error.
+func (e *LoaderPanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// call is an in-flight or completed load of a single key.
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	// panic is set if loader panicked
+	panic *LoaderPanicError
+}
+
+type failedLoad[K comparable] struct {
+	err       error
+	expiresAt time.Time
+	// item is position of the key in the expiration order
+	item *ListItem[K]
+}
+
+// loadGroup deduplicates concurrent loads of the same key
+// and remembers failed loads for a short time.
+type loadGroup[K comparable, V any] struct {
+	mu       sync.Mutex
+	calls    map[K]*call[V]
+	failures map[K]failedLoad[K]
+	// expirations keeps keys of failures from the earliest to the latest expiration time,
+	// which is the order they were added in, since all of them live for errorTTL
+	expirations DoublyLinkedList[K]
+	errorTTL    time.Duration
+}
+
+// GetOrLoad returns value associated with a given key in the cache.
+// If the key is missing, loader is called and its result is stored in the cache.
+// Concurrent calls for the same key share a single call of loader.
+//
+// Loader runs with context detached from cancellation of the caller, so a caller
+// which gives up does not fail others waiting for the same key. The caller itself
+// stops waiting and returns ctx.Err() as soon as ctx is done.
+//
+// If the cache was created with WithErrorTTL, loader errors are remembered and
+// returned without calling loader again until the error time to live passes.
+//
+// Panic of loader does not crash the process, GetOrLoad panics with *LoaderPanicError
+// in every caller waiting for the load instead.
+func (c *LRUCache[K, V]) GetOrLoad(ctx context.Context, key K, loader LoaderFunc[V]) (V, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+
+	var zero V
+	g := &c.loads
+	now := c.clock.Now()
+
+	g.mu.Lock()
+	g.expireFailures(now)
+	if failure, ok := g.failures[key]; ok {
+		g.mu.Unlock()
+		return zero, failure.err
+	}
+
+	cl, ok := g.calls[key]
+	if !ok {
+		cl = &call[V]{done: make(chan struct{})}
+		if g.calls == nil {
+			g.calls = make(map[K]*call[V])
+		}
+		g.calls[key] = cl
+
+		go c.load(context.WithoutCancel(ctx), key, cl, loader)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		if cl.panic != nil {
+			panic(cl.panic)
+		}
+
+		return cl.value, cl.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// load calls loader and stores its result in the cache.
+// Panic of loader is recovered and passed to callers waiting for the result.
+func (c *LRUCache[K, V]) load(ctx context.Context, key K, cl *call[V], loader LoaderFunc[V]) {
+	g := &c.loads
+	defer close(cl.done)
+
+	defer func() {
+		if v := recover(); v != nil {
+			cl.panic = &LoaderPanicError{Value: v, Stack: debug.Stack()}
+
+			g.mu.Lock()
+			defer g.mu.Unlock()
+
+			delete(g.calls, key)
+		}
+	}()
+
+	cl.value, cl.err = loader(ctx)
+	if cl.err == nil {
+		_, cl.err = c.Set(key, cl.value)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.calls, key)
+
+	if cl.err != nil && g.errorTTL > 0 {
+		g.addFailure(key, cl.err, c.clock.Now())
+	}
+}
+
+// addFailure remembers error of loading given key until error time to live passes.
+// It must be called with mutex held.
+func (g *loadGroup[K, V]) addFailure(key K, err error, now time.Time) {
+	g.expireFailures(now)
+
+	if g.failures == nil {
+		g.failures = make(map[K]failedLoad[K])
+	}
+
+	failure := failedLoad[K]{err: err, expiresAt: now.Add(g.errorTTL)}
+	if previous, ok := g.failures[key]; ok {
+		failure.item = previous.item
+		g.expirations.MoveToBack(failure.item)
+	} else {
+		failure.item = g.expirations.PushBack(key)
+	}
+	g.failures[key] = failure
+}
+
+// expireFailures forgets errors whose time to live passed, so keys which are not requested again
+// do not pile up. It must be called with mutex held.
+func (g *loadGroup[K, V]) expireFailures(now time.Time) {
+	for item := g.expirations.Front(); item != nil; item = g.expirations.Front() {
+		if now.Before(g.failures[item.Value].expiresAt) {
+			return
+		}
+
+		delete(g.failures, item.Value)
+		g.expirations.Remove(item)
+	}
+}
+
+// clearFailures forgets all remembered loader errors.
+func (g *loadGroup[K, V]) clearFailures() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.failures = nil
+	g.expirations.Init()
+}
@@ -0,0 +1,277 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBackend = errors.New("backend is unavailable")
+
+func TestGetOrLoadCachesValue(t *testing.T) {
+	c := NewLRUCache(5)
+	calls := 0
+	loader := func(ctx context.Context) (interface{}, error) {
+		calls++
+		return "testValue", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		value, err := c.GetOrLoad(context.Background(), "testKey", loader)
+
+		require.NoError(t, err)
+		assert.Equal(t, value, "testValue")
+	}
+
+	assert.Equal(t, calls, 1)
+	assert.True(t, c.Contains("testKey"))
+}
+
+func TestGetOrLoadReturnsCachedValue(t *testing.T) {
+	c := NewLRUCache(5)
+	c.Set("testKey", "testValue")
+
+	value, err := c.GetOrLoad(context.Background(), "testKey", func(ctx context.Context) (interface{}, error) {
+		t.Fatal("loader must not be called")
+		return nil, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, value, "testValue")
+}
+
+func TestGetOrLoadDeduplicatesConcurrentLoads(t *testing.T) {
+	c := NewLRUCache(5)
+	release := make(chan struct{})
+	var calls atomic.Int32
+	loader := func(ctx context.Context) (interface{}, error) {
+		calls.Add(1)
+		<-release
+		return "testValue", nil
+	}
+
+	wg := sync.WaitGroup{}
+	results := make([]interface{}, 10)
+
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := c.GetOrLoad(context.Background(), "testKey", loader)
+			assert.NoError(t, err)
+			results[i] = value
+		}(i)
+	}
+
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, calls.Load(), int32(1))
+	for _, value := range results {
+		assert.Equal(t, value, "testValue")
+	}
+}
+
+func TestGetOrLoadDoesNotCacheErrorsByDefault(t *testing.T) {
+	c := NewLRUCache(5)
+	calls := 0
+	loader := func(ctx context.Context) (interface{}, error) {
+		calls++
+		return nil, errBackend
+	}
+
+	_, err := c.GetOrLoad(context.Background(), "testKey", loader)
+	assert.ErrorIs(t, err, errBackend)
+
+	_, err = c.GetOrLoad(context.Background(), "testKey", loader)
+	assert.ErrorIs(t, err, errBackend)
+
+	assert.Equal(t, calls, 2)
+	assert.False(t, c.Contains("testKey"))
+}
+
+func TestGetOrLoadCachesErrorsWithErrorTTL(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(5, WithClock(clock), WithErrorTTL(time.Second))
+	calls := 0
+	loader := func(ctx context.Context) (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, errBackend
+		}
+		return "testValue", nil
+	}
+
+	_, err := c.GetOrLoad(context.Background(), "testKey", loader)
+	assert.ErrorIs(t, err, errBackend)
+
+	_, err = c.GetOrLoad(context.Background(), "testKey", loader)
+	assert.ErrorIs(t, err, errBackend)
+	assert.Equal(t, calls, 1)
+
+	clock.Advance(time.Second)
+
+	value, err := c.GetOrLoad(context.Background(), "testKey", loader)
+
+	require.NoError(t, err)
+	assert.Equal(t, value, "testValue")
+	assert.Equal(t, calls, 2)
+}
+
+func TestGetOrLoadClearForgetsErrors(t *testing.T) {
+	c := NewLRUCache(5, WithErrorTTL(time.Hour))
+	calls := 0
+	loader := func(ctx context.Context) (interface{}, error) {
+		calls++
+		return nil, errBackend
+	}
+
+	c.GetOrLoad(context.Background(), "testKey", loader)
+	c.Clear()
+	c.GetOrLoad(context.Background(), "testKey", loader)
+
+	assert.Equal(t, calls, 2)
+}
+
+func TestGetOrLoadForgetsExpiredErrors(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(5, WithClock(clock), WithErrorTTL(time.Second))
+	loader := func(ctx context.Context) (interface{}, error) {
+		return nil, errBackend
+	}
+
+	c.GetOrLoad(context.Background(), "testKey1", loader)
+	c.GetOrLoad(context.Background(), "testKey2", loader)
+	clock.Advance(time.Second / 2)
+	c.GetOrLoad(context.Background(), "testKey3", loader)
+
+	assert.Len(t, c.loads.failures, 3)
+
+	// errors of keys which are not requested again are forgotten too
+	clock.Advance(time.Second / 2)
+	c.GetOrLoad(context.Background(), "testKey4", loader)
+
+	assert.Len(t, c.loads.failures, 2)
+	assert.Equal(t, c.loads.expirations.Len(), 2)
+
+	clock.Advance(time.Second)
+	c.GetOrLoad(context.Background(), "testKey1", func(ctx context.Context) (interface{}, error) {
+		return "testValue", nil
+	})
+
+	assert.Empty(t, c.loads.failures)
+	assert.Equal(t, c.loads.expirations.Len(), 0)
+}
+
+// getOrLoadPanic returns value GetOrLoad panicked with, or nil.
+func getOrLoadPanic(c *LRUCache[Key, interface{}], key Key, loader LoaderFunc[interface{}]) (p interface{}) {
+	defer func() {
+		p = recover()
+	}()
+
+	_, _ = c.GetOrLoad(context.Background(), key, loader)
+
+	return nil
+}
+
+func TestGetOrLoadPassesLoaderPanicToCallers(t *testing.T) {
+	c := NewLRUCache(5)
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (interface{}, error) {
+		<-release
+		panic(errBackend)
+	}
+
+	var wg sync.WaitGroup
+	panics := make([]interface{}, 3)
+	for i := range panics {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			panics[i] = getOrLoadPanic(c, "testKey", loader)
+		}()
+	}
+
+	// callers coming after the panic start their own load, which panics too
+	require.Eventually(t, func() bool {
+		c.loads.mu.Lock()
+		defer c.loads.mu.Unlock()
+
+		return len(c.loads.calls) == 1
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, p := range panics {
+		var panicErr *LoaderPanicError
+		err, ok := p.(error)
+
+		require.True(t, ok, "GetOrLoad does not panic with error")
+		require.ErrorAs(t, err, &panicErr)
+		assert.ErrorIs(t, panicErr, errBackend)
+		assert.NotEmpty(t, panicErr.Stack)
+	}
+
+	// panicked load is not remembered
+	value, err := c.GetOrLoad(context.Background(), "testKey", func(ctx context.Context) (interface{}, error) {
+		return "testValue", nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, value, "testValue")
+}
+
+func TestGetOrLoadHonoursContextCancellation(t *testing.T) {
+	c := NewLRUCache(5)
+	release := make(chan struct{})
+	loaded := make(chan struct{})
+	loader := func(ctx context.Context) (interface{}, error) {
+		defer close(loaded)
+		<-release
+		// context of the loader is not cancelled together with the caller
+		assert.NoError(t, ctx.Err())
+		return "testValue", nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := c.GetOrLoad(ctx, "testKey", loader)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	<-loaded
+
+	// loaded value is still stored for other callers
+	assert.Eventually(t, func() bool { return c.Contains("testKey") }, time.Second, time.Millisecond)
+}
+
+func TestGetOrLoadRejectsTooLargeValue(t *testing.T) {
+	c := NewLRUCache(Unlimited, WithMaxCost(1), WithSizer(func(value interface{}) int64 { return 10 }))
+
+	_, err := c.GetOrLoad(context.Background(), "testKey", func(ctx context.Context) (interface{}, error) {
+		return "testValue", nil
+	})
+
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestShardedLRUCacheGetOrLoad(t *testing.T) {
+	c := NewShardedLRUCache(10, 4)
+
+	value, err := c.GetOrLoad(context.Background(), "testKey", func(ctx context.Context) (interface{}, error) {
+		return "testValue", nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, value, "testValue")
+	assert.True(t, c.Contains("testKey"))
+}
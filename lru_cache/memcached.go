@@ -0,0 +1,566 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrServerClosed = errors.New("cache server closed")
+
+const (
+	memcachedVersion       = "1.6.0"
+	memcachedMaxKeyLength  = 250
+	memcachedMaxLineLength = 2048
+	memcachedMaxItemSize   = 1 << 20
+	// memcachedMaxRelativeExptime is the largest exptime meaning number of seconds from now,
+	// larger values are unix timestamps
+	memcachedMaxRelativeExptime = 60 * 60 * 24 * 30
+)
+
+// MemcachedItem is a value stored by MemcachedServer.
+type MemcachedItem struct {
+	// Flags are opaque to the server and returned to clients as is.
+	Flags uint32
+	Data  []byte
+	// CAS is unique version of the item reported by gets and checked by cas.
+	CAS       uint64
+	expiresAt time.Time
+}
+
+// MemcachedServer serves cache over memcached text protocol, so existing memcached
+// clients and tools can use it. Supported commands are get, gets, set, add, replace,
+// cas, delete, incr, decr, flush_all, stats, version and quit.
+//
+// Expiration time of items is number of seconds from now if it does not exceed 30 days,
+// otherwise it is unix timestamp. Negative expiration time means the item is expired immediately.
+type MemcachedServer struct {
+	cache *LRUCache[string, MemcachedItem]
+	// mu serializes commands which read and modify an item
+	mu       sync.Mutex
+	cas      atomic.Uint64
+	started  time.Time
+	counters memcachedCounters
+
+	connsMu   sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+type memcachedCounters struct {
+	totalConns atomic.Uint64
+	cmdGet     atomic.Uint64
+	cmdSet     atomic.Uint64
+	cmdFlush   atomic.Uint64
+}
+
+// NewMemcachedServer returns pointer to newly created MemcachedServer storing items in given cache.
+// Cache sizer, if any, should account size of Data of items.
+func NewMemcachedServer(cache *LRUCache[string, MemcachedItem]) *MemcachedServer {
+	return &MemcachedServer{
+		cache:     cache,
+		started:   cache.clock.Now(),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on given TCP address and serves connections.
+// It always returns non-nil error, ErrServerClosed after Close.
+func (s *MemcachedServer) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(l)
+}
+
+// Serve accepts connections on given listener and serves each of them in its own goroutine.
+// It always returns non-nil error, ErrServerClosed after Close.
+func (s *MemcachedServer) Serve(l net.Listener) error {
+	if !s.track(func() { s.listeners[l] = struct{}{} }) {
+		l.Close()
+		return ErrServerClosed
+	}
+	defer s.track(func() { delete(s.listeners, l) })
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+
+			return err
+		}
+
+		tracked := s.track(func() {
+			s.conns[conn] = struct{}{}
+			s.wg.Add(1)
+		})
+		if !tracked {
+			conn.Close()
+			return ErrServerClosed
+		}
+
+		s.counters.totalConns.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+// Close closes all listeners and connections and waits until connections are released.
+func (s *MemcachedServer) Close() error {
+	s.connsMu.Lock()
+	s.closed = true
+	var err error
+	for l := range s.listeners {
+		if closeErr := l.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.connsMu.Unlock()
+
+	s.wg.Wait()
+
+	return err
+}
+
+// track calls given function with connections mutex held, unless server is closed.
+func (s *MemcachedServer) track(fn func()) bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	if s.closed {
+		return false
+	}
+	fn()
+
+	return true
+}
+
+func (s *MemcachedServer) isClosed() bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	return s.closed
+}
+
+func (s *MemcachedServer) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.connsMu.Lock()
+		delete(s.conns, conn)
+		s.connsMu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReaderSize(conn, memcachedMaxLineLength)
+	w := bufio.NewWriter(conn)
+
+	for {
+		line, err := r.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			w.WriteString("CLIENT_ERROR line too long\r\n")
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		keep := s.execute(bytes.Fields(line), r, w)
+
+		// responses to pipelined commands are written at once
+		if r.Buffered() == 0 || !keep {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		if !keep {
+			return
+		}
+	}
+}
+
+// execute runs command with given fields and writes response.
+// It returns false if connection must be closed.
+func (s *MemcachedServer) execute(fields [][]byte, r *bufio.Reader, w *bufio.Writer) bool {
+	if len(fields) == 0 {
+		w.WriteString("ERROR\r\n")
+		return true
+	}
+
+	args := make([]string, 0, len(fields)-1)
+	for _, field := range fields[1:] {
+		args = append(args, string(field))
+	}
+
+	switch command := string(fields[0]); command {
+	case "get", "gets":
+		s.get(w, args, command == "gets")
+	case "set", "add", "replace", "cas":
+		return s.store(w, r, command, args)
+	case "delete":
+		s.delete(w, args)
+	case "incr", "decr":
+		s.incr(w, args, command == "decr")
+	case "flush_all":
+		s.flushAll(w, args)
+	case "stats":
+		s.stats(w, args)
+	case "version":
+		w.WriteString("VERSION " + memcachedVersion + "\r\n")
+	case "quit":
+		return false
+	default:
+		w.WriteString("ERROR\r\n")
+	}
+
+	return true
+}
+
+func (s *MemcachedServer) get(w *bufio.Writer, keys []string, withCAS bool) {
+	if len(keys) == 0 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+
+	for _, key := range keys {
+		s.counters.cmdGet.Add(1)
+
+		item, ok := s.cache.Get(key)
+		if !ok {
+			continue
+		}
+
+		if withCAS {
+			fmt.Fprintf(w, "VALUE %s %d %d %d\r\n", key, item.Flags, len(item.Data), item.CAS)
+		} else {
+			fmt.Fprintf(w, "VALUE %s %d %d\r\n", key, item.Flags, len(item.Data))
+		}
+		w.Write(item.Data)
+		w.WriteString("\r\n")
+	}
+
+	w.WriteString("END\r\n")
+}
+
+// store handles set, add, replace and cas commands:
+//
+//	<command> <key> <flags> <exptime> <bytes> [<cas unique>] [noreply]\r\n<data>\r\n
+func (s *MemcachedServer) store(w *bufio.Writer, r *bufio.Reader, command string, args []string) bool {
+	n := 4
+	if command == "cas" {
+		n = 5
+	}
+
+	noreply, ok := parseNoreply(args, n)
+	if !ok {
+		w.WriteString("ERROR\r\n")
+		return true
+	}
+
+	key := args[0]
+	flags, flagsErr := strconv.ParseUint(args[1], 10, 32)
+	exptime, exptimeErr := strconv.ParseInt(args[2], 10, 64)
+	size, sizeErr := strconv.Atoi(args[3])
+	var unique uint64
+	var uniqueErr error
+	if command == "cas" {
+		unique, uniqueErr = strconv.ParseUint(args[4], 10, 64)
+	}
+
+	if sizeErr != nil || size < 0 {
+		// size of data is unknown, so it can not be skipped
+		w.WriteString("CLIENT_ERROR bad command line format\r\n")
+		return false
+	}
+
+	if size > memcachedMaxItemSize {
+		if _, err := io.CopyN(io.Discard, r, int64(size)+2); err != nil {
+			return false
+		}
+		w.WriteString("SERVER_ERROR object too large for cache\r\n")
+
+		return true
+	}
+
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return false
+	}
+	if !bytes.HasSuffix(data, []byte("\r\n")) {
+		w.WriteString("CLIENT_ERROR bad data chunk\r\n")
+		return false
+	}
+
+	if !validKey(key) || flagsErr != nil || exptimeErr != nil || uniqueErr != nil {
+		w.WriteString("CLIENT_ERROR bad command line format\r\n")
+		return true
+	}
+
+	s.counters.cmdSet.Add(1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, exists := s.cache.Peek(key)
+
+	var reply string
+	switch {
+	case command == "add" && exists, command == "replace" && !exists:
+		reply = "NOT_STORED"
+	case command == "cas" && !exists:
+		reply = "NOT_FOUND"
+	case command == "cas" && current.CAS != unique:
+		reply = "EXISTS"
+	default:
+		reply = s.put(key, MemcachedItem{
+			Flags:     uint32(flags),
+			Data:      data[:size],
+			expiresAt: s.expiresAt(exptime),
+		})
+	}
+
+	if !noreply {
+		w.WriteString(reply + "\r\n")
+	}
+
+	return true
+}
+
+// delete handles delete command:
+//
+//	delete <key> [noreply]\r\n
+func (s *MemcachedServer) delete(w *bufio.Writer, args []string) {
+	noreply, ok := parseNoreply(args, 1)
+	if !ok {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+
+	s.mu.Lock()
+	deleted := s.cache.Delete(args[0])
+	s.mu.Unlock()
+
+	reply := "NOT_FOUND"
+	if deleted {
+		reply = "DELETED"
+	}
+
+	if !noreply {
+		w.WriteString(reply + "\r\n")
+	}
+}
+
+// incr handles incr and decr commands. Decrementing below zero gives zero,
+// while incrementing wraps around 64 bit unsigned integer:
+//
+//	incr <key> <value> [noreply]\r\n
+func (s *MemcachedServer) incr(w *bufio.Writer, args []string, decr bool) {
+	noreply, ok := parseNoreply(args, 2)
+	if !ok {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+
+	delta, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		w.WriteString("CLIENT_ERROR invalid numeric delta argument\r\n")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var reply string
+	item, ok := s.cache.Peek(args[0])
+	if !ok {
+		reply = "NOT_FOUND"
+	} else if value, err := strconv.ParseUint(string(item.Data), 10, 64); err != nil {
+		reply = "CLIENT_ERROR cannot increment or decrement non-numeric value"
+	} else {
+		switch {
+		case !decr:
+			value += delta
+		case delta > value:
+			value = 0
+		default:
+			value -= delta
+		}
+
+		reply = strconv.FormatUint(value, 10)
+		item.Data = []byte(reply)
+		if stored := s.put(args[0], item); stored != "STORED" {
+			reply = stored
+		}
+	}
+
+	if !noreply {
+		w.WriteString(reply + "\r\n")
+	}
+}
+
+// flushAll handles flush_all command removing all items after optional delay in seconds:
+//
+//	flush_all [delay] [noreply]\r\n
+func (s *MemcachedServer) flushAll(w *bufio.Writer, args []string) {
+	noreply, ok := parseNoreply(args, 0)
+	if !ok {
+		noreply, ok = parseNoreply(args, 1)
+	}
+	if !ok {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+
+	var delay int64
+	if len(args) > 0 && args[0] != "noreply" {
+		var err error
+		if delay, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+			w.WriteString("CLIENT_ERROR bad command line format\r\n")
+			return
+		}
+	}
+
+	s.counters.cmdFlush.Add(1)
+
+	if delay > 0 {
+		time.AfterFunc(time.Duration(delay)*time.Second, s.flush)
+	} else {
+		s.flush()
+	}
+
+	if !noreply {
+		w.WriteString("OK\r\n")
+	}
+}
+
+func (s *MemcachedServer) stats(w *bufio.Writer, args []string) {
+	if len(args) > 0 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+
+	s.connsMu.Lock()
+	currConns := len(s.conns)
+	s.connsMu.Unlock()
+
+	now := s.cache.clock.Now()
+	stats := s.cache.Stats()
+
+	for _, stat := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"pid", os.Getpid()},
+		{"uptime", int64(now.Sub(s.started).Seconds())},
+		{"time", now.Unix()},
+		{"version", memcachedVersion},
+		{"curr_connections", currConns},
+		{"total_connections", s.counters.totalConns.Load()},
+		{"cmd_get", s.counters.cmdGet.Load()},
+		{"cmd_set", s.counters.cmdSet.Load()},
+		{"cmd_flush", s.counters.cmdFlush.Load()},
+		{"get_hits", stats.Hits},
+		{"get_misses", stats.Misses},
+		{"curr_items", stats.Len},
+		{"total_items", stats.Sets},
+		{"evictions", stats.Evictions},
+		{"expired_unfetched", stats.Expirations},
+		{"bytes", stats.Cost},
+		{"limit_maxbytes", stats.MaxCost},
+	} {
+		fmt.Fprintf(w, "STAT %s %v\r\n", stat.name, stat.value)
+	}
+
+	w.WriteString("END\r\n")
+}
+
+func (s *MemcachedServer) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache.Clear()
+}
+
+// put stores item with new CAS unique and returns reply to the client.
+// It must be called with mutex held.
+func (s *MemcachedServer) put(key string, item MemcachedItem) string {
+	var ttl time.Duration
+	if !item.expiresAt.IsZero() {
+		ttl = item.expiresAt.Sub(s.cache.clock.Now())
+		if ttl <= 0 {
+			// item expired before it was stored
+			s.cache.Delete(key)
+			return "STORED"
+		}
+	}
+
+	item.CAS = s.cas.Add(1)
+	if _, err := s.cache.SetWithTTL(key, item, ttl); err != nil {
+		if errors.Is(err, ErrTooLarge) {
+			return "SERVER_ERROR object too large for cache"
+		}
+
+		return "SERVER_ERROR " + err.Error()
+	}
+
+	return "STORED"
+}
+
+// expiresAt converts expiration time of the protocol to moment when item expires.
+func (s *MemcachedServer) expiresAt(exptime int64) time.Time {
+	now := s.cache.clock.Now()
+
+	switch {
+	case exptime == 0:
+		return time.Time{}
+	case exptime < 0:
+		return now
+	case exptime <= memcachedMaxRelativeExptime:
+		return now.Add(time.Duration(exptime) * time.Second)
+	default:
+		return time.Unix(exptime, 0)
+	}
+}
+
+// parseNoreply checks that there are n arguments followed by optional noreply.
+func parseNoreply(args []string, n int) (noreply bool, ok bool) {
+	switch {
+	case len(args) == n:
+		return false, true
+	case len(args) == n+1 && args[n] == "noreply":
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+func validKey(key string) bool {
+	if len(key) > memcachedMaxKeyLength {
+		return false
+	}
+
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+
+	return true
+}
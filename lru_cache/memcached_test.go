@@ -0,0 +1,257 @@
+package cache
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memcachedConn struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// startMemcached serves given cache on loopback listener and returns connection to it.
+func startMemcached(t *testing.T, c *LRUCache[string, MemcachedItem]) (*MemcachedServer, *memcachedConn) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewMemcachedServer(c)
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(l)
+	}()
+
+	t.Cleanup(func() {
+		server.Close()
+		assert.ErrorIs(t, <-served, ErrServerClosed)
+	})
+
+	return server, dialMemcached(t, l.Addr().String())
+}
+
+func dialMemcached(t *testing.T, addr string) *memcachedConn {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return &memcachedConn{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+// roundTrip sends request and checks that server responds with expected bytes.
+func (c *memcachedConn) roundTrip(request, expected string) {
+	c.t.Helper()
+
+	require.NoError(c.t, c.conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	_, err := io.WriteString(c.conn, request)
+	require.NoError(c.t, err)
+
+	response := make([]byte, len(expected))
+	_, err = io.ReadFull(c.r, response)
+	require.NoError(c.t, err, "request %q", request)
+	assert.Equal(c.t, string(response), expected, "request %q", request)
+}
+
+func TestMemcachedSetAndGet(t *testing.T) {
+	_, c := startMemcached(t, New[string, MemcachedItem](10))
+
+	c.roundTrip("set testKey1 42 0 10\r\ntestValue1\r\n", "STORED\r\n")
+	c.roundTrip("set testKey2 0 0 0\r\n\r\n", "STORED\r\n")
+	c.roundTrip("get testKey1\r\n", "VALUE testKey1 42 10\r\ntestValue1\r\nEND\r\n")
+	c.roundTrip("get testKey1 nonexistentKey testKey2\r\n",
+		"VALUE testKey1 42 10\r\ntestValue1\r\nVALUE testKey2 0 0\r\n\r\nEND\r\n")
+	c.roundTrip("get nonexistentKey\r\n", "END\r\n")
+}
+
+func TestMemcachedAddAndReplace(t *testing.T) {
+	_, c := startMemcached(t, New[string, MemcachedItem](10))
+
+	c.roundTrip("replace testKey 0 0 1\r\na\r\n", "NOT_STORED\r\n")
+	c.roundTrip("add testKey 0 0 1\r\nb\r\n", "STORED\r\n")
+	c.roundTrip("add testKey 0 0 1\r\nc\r\n", "NOT_STORED\r\n")
+	c.roundTrip("replace testKey 0 0 1\r\nd\r\n", "STORED\r\n")
+	c.roundTrip("get testKey\r\n", "VALUE testKey 0 1\r\nd\r\nEND\r\n")
+}
+
+func TestMemcachedGetsAndCas(t *testing.T) {
+	_, c := startMemcached(t, New[string, MemcachedItem](10))
+
+	c.roundTrip("cas testKey 0 0 1 1\r\na\r\n", "NOT_FOUND\r\n")
+	c.roundTrip("set testKey 0 0 1\r\na\r\n", "STORED\r\n")
+	c.roundTrip("gets testKey\r\n", "VALUE testKey 0 1 1\r\na\r\nEND\r\n")
+	c.roundTrip("cas testKey 0 0 1 2\r\nb\r\n", "EXISTS\r\n")
+	c.roundTrip("cas testKey 0 0 1 1\r\nc\r\n", "STORED\r\n")
+	c.roundTrip("gets testKey\r\n", "VALUE testKey 0 1 2\r\nc\r\nEND\r\n")
+}
+
+func TestMemcachedDelete(t *testing.T) {
+	_, c := startMemcached(t, New[string, MemcachedItem](10))
+
+	c.roundTrip("set testKey 0 0 1\r\na\r\n", "STORED\r\n")
+	c.roundTrip("delete testKey\r\n", "DELETED\r\n")
+	c.roundTrip("delete testKey\r\n", "NOT_FOUND\r\n")
+	c.roundTrip("get testKey\r\n", "END\r\n")
+}
+
+func TestMemcachedIncrAndDecr(t *testing.T) {
+	_, c := startMemcached(t, New[string, MemcachedItem](10))
+
+	c.roundTrip("incr counter 1\r\n", "NOT_FOUND\r\n")
+	c.roundTrip("set counter 5 0 2\r\n10\r\n", "STORED\r\n")
+	c.roundTrip("incr counter 5\r\n", "15\r\n")
+	c.roundTrip("decr counter 20\r\n", "0\r\n")
+	c.roundTrip("set counter 5 0 20\r\n18446744073709551615\r\n", "STORED\r\n")
+	c.roundTrip("incr counter 2\r\n", "1\r\n")
+	// flags are kept
+	c.roundTrip("get counter\r\n", "VALUE counter 5 1\r\n1\r\nEND\r\n")
+
+	c.roundTrip("set text 0 0 3\r\nabc\r\n", "STORED\r\n")
+	c.roundTrip("incr text 1\r\n", "CLIENT_ERROR cannot increment or decrement non-numeric value\r\n")
+	c.roundTrip("incr counter x\r\n", "CLIENT_ERROR invalid numeric delta argument\r\n")
+}
+
+func TestMemcachedExptime(t *testing.T) {
+	clock := newFakeClock()
+	_, c := startMemcached(t, New[string, MemcachedItem](10, WithClock(clock)))
+
+	absolute := clock.Now().Add(time.Hour).Unix()
+
+	c.roundTrip("set relative 0 60 1\r\na\r\n", "STORED\r\n")
+	c.roundTrip("set absolute 0 "+strconv.FormatInt(absolute, 10)+" 1\r\nb\r\n", "STORED\r\n")
+	c.roundTrip("set negative 0 -1 1\r\nc\r\n", "STORED\r\n")
+	c.roundTrip("set forever 0 0 1\r\nd\r\n", "STORED\r\n")
+	c.roundTrip("get negative\r\n", "END\r\n")
+
+	clock.Advance(time.Minute)
+
+	c.roundTrip("get relative absolute\r\n", "VALUE absolute 0 1\r\nb\r\nEND\r\n")
+	// incr keeps expiration time
+	c.roundTrip("set counter 0 120 1\r\n1\r\n", "STORED\r\n")
+	c.roundTrip("incr counter 1\r\n", "2\r\n")
+
+	clock.Advance(time.Hour)
+
+	c.roundTrip("get absolute counter forever\r\n", "VALUE forever 0 1\r\nd\r\nEND\r\n")
+}
+
+func TestMemcachedNoreply(t *testing.T) {
+	_, c := startMemcached(t, New[string, MemcachedItem](10))
+
+	c.roundTrip(
+		"set testKey 0 0 1 noreply\r\na\r\n"+
+			"add testKey 0 0 1 noreply\r\nb\r\n"+
+			"set counter 0 0 1 noreply\r\n1\r\n"+
+			"incr counter 1 noreply\r\n"+
+			"delete nonexistentKey noreply\r\n"+
+			"get testKey counter\r\n",
+		"VALUE testKey 0 1\r\na\r\nVALUE counter 0 1\r\n2\r\nEND\r\n",
+	)
+}
+
+func TestMemcachedFlushAll(t *testing.T) {
+	_, c := startMemcached(t, New[string, MemcachedItem](10))
+
+	c.roundTrip("set testKey 0 0 1\r\na\r\n", "STORED\r\n")
+	c.roundTrip("flush_all\r\n", "OK\r\n")
+	c.roundTrip("get testKey\r\n", "END\r\n")
+	c.roundTrip("flush_all 0 noreply\r\nversion\r\n", "VERSION "+memcachedVersion+"\r\n")
+}
+
+func TestMemcachedEvictsLeastRecentlyUsed(t *testing.T) {
+	_, c := startMemcached(t, New[string, MemcachedItem](2))
+
+	c.roundTrip("set testKey1 0 0 1\r\na\r\n", "STORED\r\n")
+	c.roundTrip("set testKey2 0 0 1\r\nb\r\n", "STORED\r\n")
+	c.roundTrip("get testKey1\r\n", "VALUE testKey1 0 1\r\na\r\nEND\r\n")
+	c.roundTrip("set testKey3 0 0 1\r\nc\r\n", "STORED\r\n")
+	c.roundTrip("get testKey1 testKey2 testKey3\r\n",
+		"VALUE testKey1 0 1\r\na\r\nVALUE testKey3 0 1\r\nc\r\nEND\r\n")
+}
+
+func TestMemcachedTooLarge(t *testing.T) {
+	sizer := func(item MemcachedItem) int64 { return int64(len(item.Data)) }
+	_, c := startMemcached(t, New[string, MemcachedItem](10, WithMaxCost(4), WithSizer(sizer)))
+
+	c.roundTrip("set testKey 0 0 5\r\nabcde\r\n", "SERVER_ERROR object too large for cache\r\n")
+	c.roundTrip("set testKey 0 0 4\r\nabcd\r\n", "STORED\r\n")
+}
+
+func TestMemcachedStats(t *testing.T) {
+	_, c := startMemcached(t, New[string, MemcachedItem](10))
+
+	c.roundTrip("set testKey 0 0 1\r\na\r\n", "STORED\r\n")
+	c.roundTrip("get testKey nonexistentKey\r\n", "VALUE testKey 0 1\r\na\r\nEND\r\n")
+
+	_, err := io.WriteString(c.conn, "stats\r\n")
+	require.NoError(t, err)
+
+	stats := make(map[string]string)
+	for {
+		line, err := c.r.ReadString('\n')
+		require.NoError(t, err)
+		if line == "END\r\n" {
+			break
+		}
+
+		fields := strings.Fields(line)
+		require.Len(t, fields, 3)
+		assert.Equal(t, fields[0], "STAT")
+		stats[fields[1]] = fields[2]
+	}
+
+	assert.Equal(t, stats["curr_items"], "1")
+	assert.Equal(t, stats["cmd_get"], "2")
+	assert.Equal(t, stats["cmd_set"], "1")
+	assert.Equal(t, stats["get_hits"], "1")
+	assert.Equal(t, stats["get_misses"], "1")
+	assert.Equal(t, stats["curr_connections"], "1")
+}
+
+func TestMemcachedErrors(t *testing.T) {
+	_, c := startMemcached(t, New[string, MemcachedItem](10))
+
+	c.roundTrip("unknown\r\n", "ERROR\r\n")
+	c.roundTrip("\r\n", "ERROR\r\n")
+	c.roundTrip("get\r\n", "ERROR\r\n")
+	c.roundTrip("delete\r\n", "ERROR\r\n")
+	c.roundTrip("set "+strings.Repeat("k", memcachedMaxKeyLength+1)+" 0 0 1\r\na\r\n",
+		"CLIENT_ERROR bad command line format\r\n")
+	c.roundTrip("set testKey x 0 1\r\na\r\n", "CLIENT_ERROR bad command line format\r\n")
+	c.roundTrip("set testKey 0 0 1\r\nab\r\n", "CLIENT_ERROR bad data chunk\r\n")
+
+	// connection is closed after bad data chunk
+	_, err := c.r.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestMemcachedQuit(t *testing.T) {
+	_, c := startMemcached(t, New[string, MemcachedItem](10))
+
+	c.roundTrip("version\r\nquit\r\n", "VERSION "+memcachedVersion+"\r\n")
+
+	_, err := c.r.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestMemcachedCloseDisconnectsClients(t *testing.T) {
+	server, c := startMemcached(t, New[string, MemcachedItem](10))
+
+	c.roundTrip("version\r\n", "VERSION "+memcachedVersion+"\r\n")
+	require.NoError(t, server.Close())
+
+	_, err := c.r.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+}
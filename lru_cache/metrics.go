@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// StatsProvider is implemented by caches which can report their statistics.
+type StatsProvider interface {
+	Stats() Stats
+}
+
+type metric struct {
+	name      string
+	help      string
+	kind      string
+	valueFunc func(s Stats) float64
+}
+
+var metrics = []metric{
+	{"hits_total", "Number of cache hits.", "counter", func(s Stats) float64 { return float64(s.Hits) }},
+	{"misses_total", "Number of cache misses.", "counter", func(s Stats) float64 { return float64(s.Misses) }},
+	{"sets_total", "Number of stored values.", "counter", func(s Stats) float64 { return float64(s.Sets) }},
+	{
+		"evictions_total", "Number of entries evicted to free space.", "counter",
+		func(s Stats) float64 { return float64(s.Evictions) },
+	},
+	{
+		"expirations_total", "Number of entries removed after their time to live.", "counter",
+		func(s Stats) float64 { return float64(s.Expirations) },
+	},
+	{"hit_ratio", "Ratio of hits to all lookups.", "gauge", Stats.HitRatio},
+	{"entries", "Number of entries in the cache.", "gauge", func(s Stats) float64 { return float64(s.Len) }},
+	{"cost", "Total cost of entries in the cache.", "gauge", func(s Stats) float64 { return float64(s.Cost) }},
+	{
+		"max_cost", "Maximum total cost of entries, zero if not limited.", "gauge",
+		func(s Stats) float64 { return float64(s.MaxCost) },
+	},
+}
+
+// MetricsExporter renders statistics of registered caches in Prometheus text exposition format.
+// It implements http.Handler, so it can be mounted to be scraped by Prometheus.
+type MetricsExporter struct {
+	mu        sync.Mutex
+	namespace string
+	caches    map[string]StatsProvider
+}
+
+// NewMetricsExporter returns pointer to newly created MetricsExporter.
+// Names of all metrics are prefixed with given namespace.
+func NewMetricsExporter(namespace string) *MetricsExporter {
+	return &MetricsExporter{
+		namespace: namespace,
+		caches:    make(map[string]StatsProvider),
+	}
+}
+
+// Register adds cache to the exporter. Its metrics are labeled with given name.
+// Registering another cache with the same name replaces the previous one.
+func (e *MetricsExporter) Register(name string, cache StatsProvider) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.caches[name] = cache
+}
+
+// Unregister removes cache with given name from the exporter.
+func (e *MetricsExporter) Unregister(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.caches, name)
+}
+
+// ServeHTTP writes metrics of all registered caches.
+func (e *MetricsExporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	buf := bufio.NewWriter(w)
+	e.writeMetrics(buf)
+	// nothing can be done if client went away
+	_ = buf.Flush()
+}
+
+func (e *MetricsExporter) writeMetrics(w *bufio.Writer) {
+	e.mu.Lock()
+	names := make([]string, 0, len(e.caches))
+	stats := make(map[string]Stats, len(e.caches))
+	for name, cache := range e.caches {
+		names = append(names, name)
+		stats[name] = cache.Stats()
+	}
+	e.mu.Unlock()
+
+	sort.Strings(names)
+
+	for _, m := range metrics {
+		name := m.name
+		if e.namespace != "" {
+			name = e.namespace + "_" + name
+		}
+
+		fmt.Fprintf(w, "# HELP %s %s\n", name, m.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, m.kind)
+
+		for _, cacheName := range names {
+			fmt.Fprintf(w, "%s{cache=\"%s\"} %g\n", name, escapeLabelValue(cacheName), m.valueFunc(stats[cacheName]))
+		}
+	}
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
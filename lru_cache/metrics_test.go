@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCacheStatsCounters(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCache(2, WithClock(clock))
+
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Set("testKey3", "testValue3")
+	c.SetWithTTL("testKey3", "newTestValue3", time.Minute)
+	c.Get("testKey1")
+	c.Get("testKey2")
+	// peek does not affect hits and misses
+	c.Peek("testKey2")
+
+	clock.Advance(time.Minute)
+	c.Get("testKey3")
+
+	stats := c.Stats()
+
+	assert.Equal(t, stats.Sets, uint64(4))
+	assert.Equal(t, stats.Hits, uint64(1))
+	assert.Equal(t, stats.Misses, uint64(2))
+	assert.Equal(t, stats.Evictions, uint64(1))
+	assert.Equal(t, stats.Expirations, uint64(1))
+	assert.Equal(t, stats.Len, 1)
+	assert.InDelta(t, stats.HitRatio(), 1.0/3, 1e-9)
+}
+
+func TestStatsHitRatioWithoutLookups(t *testing.T) {
+	assert.Equal(t, Stats{}.HitRatio(), 0.0)
+}
+
+func TestShardedLRUCacheStatsCounters(t *testing.T) {
+	c := NewShardedLRUCache(10, 4)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Get("testKey1")
+	c.Get("nonexistentKey")
+
+	stats := c.Stats()
+
+	assert.Equal(t, stats.Len, 2)
+	assert.Equal(t, stats.Sets, uint64(2))
+	assert.Equal(t, stats.Hits, uint64(1))
+	assert.Equal(t, stats.Misses, uint64(1))
+}
+
+func TestMetricsExporter(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("testKey", "testValue")
+	c.Get("testKey")
+	c.Get("nonexistentKey")
+
+	exporter := NewMetricsExporter("lru")
+	exporter.Register("users", c)
+	exporter.Register(`weird"name`, NewLRUCache(1))
+
+	rec := httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body
+
+	assert.Equal(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8")
+	assert.Contains(t, body.String(), "# HELP lru_hits_total Number of cache hits.\n# TYPE lru_hits_total counter\n")
+	assert.Contains(t, body.String(), "lru_hits_total{cache=\"users\"} 1\n")
+	assert.Contains(t, body.String(), "lru_misses_total{cache=\"users\"} 1\n")
+	assert.Contains(t, body.String(), "lru_hit_ratio{cache=\"users\"} 0.5\n")
+	assert.Contains(t, body.String(), "lru_entries{cache=\"users\"} 1\n")
+	assert.Contains(t, body.String(), "lru_entries{cache=\"weird\\\"name\"} 0\n")
+
+	exporter.Unregister("users")
+
+	rec = httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.NotContains(t, rec.Body.String(), "users")
+}
@@ -0,0 +1,146 @@
+package cache
+
+import "time"
+
+// Clock provides current time to the cache.
+// It can be replaced in tests to control expiration deterministically.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+type options struct {
+	defaultTTL      time.Duration
+	clock           Clock
+	janitorInterval time.Duration
+	maxCost         int64
+	errorTTL        time.Duration
+	codec           Codec
+	compactGarbage  int64
+	notifyReplaced  bool
+	// sizer holds Sizer[V], it is checked against value type of the cache on construction
+	sizer interface{}
+}
+
+// Option configures optional behaviour of a cache.
+type Option func(*options)
+
+func newOptions(opts []Option) options {
+	o := options{
+		clock: systemClock{},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// changed returns names of options which differ from defaults.
+func (o options) changed() []string {
+	var names []string
+
+	for _, opt := range []struct {
+		name    string
+		changed bool
+	}{
+		{"WithDefaultTTL", o.defaultTTL > 0},
+		{"WithClock", o.clock != systemClock{}},
+		{"WithJanitor", o.janitorInterval > 0},
+		{"WithMaxCost", o.maxCost > 0},
+		{"WithErrorTTL", o.errorTTL > 0},
+		{"WithSizer", o.sizer != nil},
+		{"WithCodec", o.codec != nil},
+		{"WithCompactionThreshold", o.compactGarbage > 0},
+		{"WithNotifyReplaced", o.notifyReplaced},
+	} {
+		if opt.changed {
+			names = append(names, opt.name)
+		}
+	}
+
+	return names
+}
+
+// WithDefaultTTL sets time to live applied to entries stored with Set.
+// Non-positive ttl means entries never expire, which is the default.
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.defaultTTL = ttl
+	}
+}
+
+// WithClock sets clock used to check expiration of entries.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithJanitor starts background goroutine which removes expired entries
+// every given interval. The goroutine runs until Stop is called on the cache.
+func WithJanitor(interval time.Duration) Option {
+	return func(o *options) {
+		o.janitorInterval = interval
+	}
+}
+
+// WithNotifyReplaced makes OnEvict callback receive values overwritten by Set with EvictReplaced reason.
+// They are not reported by default, since the new value may be the same object, e.g. an open file.
+func WithNotifyReplaced(notify bool) Option {
+	return func(o *options) {
+		o.notifyReplaced = notify
+	}
+}
+
+// WithErrorTTL makes GetOrLoad remember loader errors for given time to live,
+// so failing backend is not called for the key again until it passes.
+// Non-positive ttl means errors are not remembered, which is the default.
+func WithErrorTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.errorTTL = ttl
+	}
+}
+
+// Sizer computes cost of a value, e.g. its size in bytes.
+type Sizer[V any] func(value V) int64
+
+// WithMaxCost limits total cost of entries stored in the cache.
+// The least recently used entries are evicted until total cost fits the budget.
+// Non-positive cost means total cost is not limited, which is the default.
+func WithMaxCost(cost int64) Option {
+	return func(o *options) {
+		o.maxCost = cost
+	}
+}
+
+// WithSizer sets function computing cost of values stored with Set and SetWithTTL.
+// Without sizer such values cost nothing.
+func WithSizer[V any](sizer Sizer[V]) Option {
+	return func(o *options) {
+		o.sizer = sizer
+	}
+}
+
+// WithCodec sets codec used by TieredCache to serialize entries spilled to disk.
+// GobCodec is used by default.
+func WithCodec(codec Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// WithCompactionThreshold sets how many bytes of stale records TieredCache tolerates
+// in its disk file before it is compacted in the background.
+// Compaction also needs stale records to take more space than live ones.
+func WithCompactionThreshold(bytes int64) Option {
+	return func(o *options) {
+		o.compactGarbage = bytes
+	}
+}
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PeerPath is a path prefix PeerGroup serves requests of other peers on.
+const PeerPath = "/_peers/"
+
+// DefaultPeerTimeout is a default time limit of a request to a peer.
+const DefaultPeerTimeout = 5 * time.Second
+
+// PeerLoaderFunc loads value of given key from the source of truth.
+type PeerLoaderFunc func(ctx context.Context, key Key) ([]byte, error)
+
+// PeerOption configures optional behaviour of a PeerGroup.
+type PeerOption func(*PeerGroup)
+
+// WithPeerClient sets HTTP client used to request values from peers.
+// http.DefaultClient is used by default.
+func WithPeerClient(client *http.Client) PeerOption {
+	return func(g *PeerGroup) {
+		g.client = client
+	}
+}
+
+// WithPeerTimeout sets time limit of a request to a peer, including reading the value.
+// Non-positive timeout means no limit. DefaultPeerTimeout is used by default.
+func WithPeerTimeout(timeout time.Duration) PeerOption {
+	return func(g *PeerGroup) {
+		g.timeout = timeout
+	}
+}
+
+// WithVirtualNodes sets number of virtual nodes every peer is placed on the hash ring with.
+func WithVirtualNodes(n int) PeerOption {
+	return func(g *PeerGroup) {
+		g.virtualNodes = n
+	}
+}
+
+// PeerGroup is a cache shared by several processes. Every key is owned by one of the peers,
+// which is chosen with consistent hashing, so the value is loaded once for all of them.
+// Misses are forwarded to the owner over HTTP, and if it is not available or does not respond in time,
+// value is loaded locally. Errors reported by the owner are returned as is.
+// Values received from peers are cached locally too.
+//
+// Peers are identified by base URLs and serve each other with ServeHTTP on PeerPath.
+type PeerGroup struct {
+	self         string
+	cache        *LRUCache[Key, []byte]
+	load         PeerLoaderFunc
+	client       *http.Client
+	timeout      time.Duration
+	virtualNodes int
+
+	mu   sync.RWMutex
+	ring *HashRing
+}
+
+// NewPeerGroup returns pointer to newly created PeerGroup of the peer with given base URL.
+// Peers are base URLs of all peers of the group, the peer itself is added if missing.
+func NewPeerGroup(
+	self string, peers []string, cache *LRUCache[Key, []byte], load PeerLoaderFunc, opts ...PeerOption,
+) *PeerGroup {
+	g := &PeerGroup{
+		self:    self,
+		cache:   cache,
+		load:    load,
+		client:  http.DefaultClient,
+		timeout: DefaultPeerTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	g.SetPeers(peers...)
+
+	return g
+}
+
+// SetPeers replaces peers of the group. Keys owned by removed peers
+// are distributed among remaining ones.
+func (g *PeerGroup) SetPeers(peers ...string) {
+	ring := NewHashRing(g.virtualNodes, nil)
+	ring.Add(g.self)
+	for _, peer := range peers {
+		if peer != g.self {
+			ring.Add(peer)
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.ring = ring
+}
+
+// Owner returns base URL of the peer owning given key.
+func (g *PeerGroup) Owner(key Key) string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.ring.Get(string(key))
+}
+
+// Get returns value of given key from local cache, from the peer owning the key,
+// or from the loader if the key is owned by this peer or the owner is not available.
+// Error response of the owner, e.g. failure of its loader, is returned wrapping ErrUnexpectedResponse.
+// Concurrent misses of the same key are deduplicated.
+func (g *PeerGroup) Get(ctx context.Context, key Key) ([]byte, error) {
+	owner := g.Owner(key)
+	if owner == g.self {
+		return g.loadLocally(ctx, key)
+	}
+
+	return g.cache.GetOrLoad(ctx, key, func(ctx context.Context) ([]byte, error) {
+		value, err := g.fetch(ctx, owner, key)
+		if err != nil && !errors.Is(err, ErrUnexpectedResponse) {
+			// owner is down or does not respond, so the value is loaded here
+			return g.load(ctx, key)
+		}
+
+		return value, err
+	})
+}
+
+// ServeHTTP serves values of keys owned by this peer to other peers.
+// Requested values are never forwarded further, so peers with different views
+// of the group do not bounce requests.
+func (g *PeerGroup) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, PeerPath) {
+		http.NotFound(w, r)
+		return
+	}
+
+	value, err := g.loadLocally(r.Context(), Key(strings.TrimPrefix(r.URL.Path, PeerPath)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	// nothing can be done if peer went away
+	_, _ = w.Write(value)
+}
+
+func (g *PeerGroup) loadLocally(ctx context.Context, key Key) ([]byte, error) {
+	return g.cache.GetOrLoad(ctx, key, func(ctx context.Context) ([]byte, error) {
+		return g.load(ctx, key)
+	})
+}
+
+// fetch requests value of given key from given peer.
+// Responses other than OK are reported with ErrUnexpectedResponse.
+func (g *PeerGroup) fetch(ctx context.Context, peer string, key Key) ([]byte, error) {
+	// loads are not canceled by callers, so hanging peer must not block them forever
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	u := strings.TrimSuffix(peer, "/") + PeerPath + url.PathEscape(string(key))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: %s", ErrUnexpectedResponse, resp.Status, readMessage(resp))
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPeer struct {
+	group  *PeerGroup
+	server *httptest.Server
+	loads  atomic.Int64
+}
+
+// startPeers runs given number of peers on loopback, every one loading values
+// as "<key>@<index of peer>", so it is visible which peer loaded the value.
+func startPeers(t *testing.T, n int) []*testPeer {
+	t.Helper()
+
+	peers := make([]*testPeer, n)
+	urls := make([]string, n)
+
+	for i := range peers {
+		p := &testPeer{}
+		p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p.group.ServeHTTP(w, r)
+		}))
+		t.Cleanup(p.server.Close)
+
+		peers[i] = p
+		urls[i] = p.server.URL
+	}
+
+	for i, p := range peers {
+		i, p := i, p
+		load := func(_ context.Context, key Key) ([]byte, error) {
+			p.loads.Add(1)
+			return []byte(string(key) + "@" + strconv.Itoa(i)), nil
+		}
+		p.group = NewPeerGroup(urls[i], urls, New[Key, []byte](100), load, WithPeerClient(p.server.Client()))
+	}
+
+	return peers
+}
+
+func peerIndex(t *testing.T, peers []*testPeer, url string) int {
+	t.Helper()
+
+	for i, p := range peers {
+		if p.server.URL == url {
+			return i
+		}
+	}
+
+	require.Failf(t, "unknown peer", url)
+
+	return -1
+}
+
+func TestPeerGroupLoadsOnOwner(t *testing.T) {
+	peers := startPeers(t, 3)
+
+	for i := 0; i < 30; i++ {
+		key := Key("testKey" + strconv.Itoa(i))
+		owner := peerIndex(t, peers, peers[0].group.Owner(key))
+
+		for _, p := range peers {
+			// every peer agrees on the owner
+			assert.Equal(t, p.group.Owner(key), peers[owner].server.URL)
+
+			value, err := p.group.Get(context.Background(), key)
+
+			require.NoError(t, err)
+			assert.Equal(t, string(value), string(key)+"@"+strconv.Itoa(owner))
+		}
+	}
+
+	total := int64(0)
+	for _, p := range peers {
+		// keys are spread among peers
+		assert.Greater(t, p.loads.Load(), int64(0))
+		total += p.loads.Load()
+	}
+
+	// every key is loaded once in the whole group
+	assert.Equal(t, total, int64(30))
+}
+
+func TestPeerGroupCachesValuesFromPeers(t *testing.T) {
+	peers := startPeers(t, 2)
+
+	key := ownedKey(peers[0].group, peers[1].server.URL)
+
+	for i := 0; i < 3; i++ {
+		_, err := peers[0].group.Get(context.Background(), key)
+		require.NoError(t, err)
+	}
+
+	assert.True(t, peers[0].group.cache.Contains(key))
+	assert.Equal(t, peers[1].loads.Load(), int64(1))
+}
+
+func TestPeerGroupFallsBackWhenPeerIsDown(t *testing.T) {
+	peers := startPeers(t, 3)
+
+	key := ownedKey(peers[0].group, peers[2].server.URL)
+
+	peers[2].server.Close()
+
+	value, err := peers[0].group.Get(context.Background(), key)
+
+	require.NoError(t, err)
+	assert.Equal(t, string(value), string(key)+"@0")
+	assert.Equal(t, peers[0].loads.Load(), int64(1))
+
+	// without the peer its keys are owned by remaining ones
+	peers[0].group.SetPeers(peers[0].server.URL, peers[1].server.URL)
+
+	assert.NotEqual(t, peers[0].group.Owner(key), peers[2].server.URL)
+}
+
+func TestPeerGroupDeduplicatesConcurrentMisses(t *testing.T) {
+	peers := startPeers(t, 2)
+
+	key := ownedKey(peers[0].group, peers[1].server.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := peers[0].group.Get(context.Background(), key)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, peers[1].loads.Load(), int64(1))
+	assert.Equal(t, peers[0].loads.Load(), int64(0))
+}
+
+func TestPeerGroupLoaderError(t *testing.T) {
+	errBackend := errors.New("backend is down")
+	load := func(context.Context, Key) ([]byte, error) {
+		return nil, errBackend
+	}
+	g := NewPeerGroup("http://self", nil, New[Key, []byte](10), load)
+
+	_, err := g.Get(context.Background(), "testKey")
+
+	assert.ErrorIs(t, err, errBackend)
+
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, PeerPath+"testKey", nil))
+
+	assert.Equal(t, rec.Code, http.StatusInternalServerError)
+
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, PeerPath+"testKey", nil))
+
+	assert.Equal(t, rec.Code, http.StatusMethodNotAllowed)
+}
+
+// ownedKey returns key owned by given peer of the group.
+func ownedKey(g *PeerGroup, peer string) Key {
+	key := Key("testKey")
+	for g.Owner(key) != peer {
+		key += "x"
+	}
+
+	return key
+}
+
+func TestPeerGroupReturnsOwnerError(t *testing.T) {
+	owner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "backend is down", http.StatusInternalServerError)
+	}))
+	defer owner.Close()
+
+	loads := atomic.Int64{}
+	load := func(context.Context, Key) ([]byte, error) {
+		loads.Add(1)
+		return []byte("testValue"), nil
+	}
+	g := NewPeerGroup("http://self", []string{owner.URL}, New[Key, []byte](10), load)
+
+	_, err := g.Get(context.Background(), ownedKey(g, owner.URL))
+
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+	assert.ErrorContains(t, err, "backend is down")
+	// owner is up, so its failure is not hidden by loading the value here
+	assert.Equal(t, loads.Load(), int64(0))
+}
+
+func TestPeerGroupFallsBackWhenPeerHangs(t *testing.T) {
+	owner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer owner.Close()
+
+	load := func(_ context.Context, key Key) ([]byte, error) {
+		return []byte(string(key) + "@self"), nil
+	}
+	g := NewPeerGroup("http://self", []string{owner.URL}, New[Key, []byte](10), load,
+		WithPeerTimeout(50*time.Millisecond))
+	key := ownedKey(g, owner.URL)
+
+	start := time.Now()
+	value, err := g.Get(context.Background(), key)
+
+	require.NoError(t, err)
+	assert.Equal(t, string(value), string(key)+"@self")
+	assert.Less(t, time.Since(start), DefaultPeerTimeout)
+}
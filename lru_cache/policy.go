@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownPolicy     = errors.New("unknown eviction policy")
+	ErrUnsupportedOption = errors.New("option is not supported by eviction policy")
+)
+
+// Policy is an eviction policy of a cache.
+type Policy int
+
+const (
+	// PolicyLRU evicts the least recently used entries.
+	PolicyLRU Policy = iota
+	// PolicyLFU evicts the least frequently used entries.
+	PolicyLFU
+	// Policy2Q keeps entries seen once apart from frequently used ones,
+	// so a single scan does not flush the hot set.
+	Policy2Q
+	// PolicyARC adapts balance between recently and frequently used entries to the workload.
+	PolicyARC
+)
+
+func (p Policy) String() string {
+	switch p {
+	case PolicyLRU:
+		return "lru"
+	case PolicyLFU:
+		return "lfu"
+	case Policy2Q:
+		return "2q"
+	case PolicyARC:
+		return "arc"
+	default:
+		return "unknown"
+	}
+}
+
+// NewWithPolicy returns newly created cache with given eviction policy and capacity.
+// Options are supported by PolicyLRU only, other policies return ErrUnsupportedOption
+// if any option changes default behaviour.
+func NewWithPolicy[K comparable, V any](policy Policy, capacity int, opts ...Option) (Cache[K, V], error) {
+	switch policy {
+	case PolicyLRU:
+		return New[K, V](capacity, opts...), nil
+	case PolicyLFU:
+		return withoutOptions[K, V](policy, NewLFU[K, V](capacity), opts)
+	case Policy2Q:
+		return withoutOptions[K, V](policy, New2Q[K, V](capacity), opts)
+	case PolicyARC:
+		return withoutOptions[K, V](policy, NewARC[K, V](capacity), opts)
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownPolicy, policy)
+	}
+}
+
+// withoutOptions returns cache of a policy which supports no options,
+// or ErrUnsupportedOption if any option changes default behaviour.
+func withoutOptions[K comparable, V any](policy Policy, c Cache[K, V], opts []Option) (Cache[K, V], error) {
+	if names := newOptions(opts).changed(); len(names) > 0 {
+		return nil, fmt.Errorf("%w: %s does not support %s", ErrUnsupportedOption, policy, strings.Join(names, ", "))
+	}
+
+	return c, nil
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// lruList keeps entries in recency order and allows to find them by key.
+// It is a building block of policies which consist of several LRU lists.
+// It is not safe for concurrent use.
+type lruList[K comparable, V any] struct {
+	queue   DoublyLinkedList[*entry[K, V]]
+	storage map[K]*ListItem[*entry[K, V]]
+}
+
+func newLRUList[K comparable, V any]() *lruList[K, V] {
+	return &lruList[K, V]{
+		storage: make(map[K]*ListItem[*entry[K, V]]),
+	}
+}
+
+func (l *lruList[K, V]) len() int {
+	return l.queue.Len()
+}
+
+func (l *lruList[K, V]) contains(key K) bool {
+	_, ok := l.storage[key]
+
+	return ok
+}
+
+// peek returns entry with given key without updating its recency.
+func (l *lruList[K, V]) peek(key K) (*entry[K, V], bool) {
+	listItem, ok := l.storage[key]
+	if !ok {
+		return nil, false
+	}
+
+	return listItem.Value, true
+}
+
+// get returns entry with given key and moves it to the front.
+func (l *lruList[K, V]) get(key K) (*entry[K, V], bool) {
+	listItem, ok := l.storage[key]
+	if !ok {
+		return nil, false
+	}
+
+	l.queue.MoveToFront(listItem)
+
+	return listItem.Value, true
+}
+
+// add puts new entry to the front, key must not be in the list.
+func (l *lruList[K, V]) add(key K, value V) {
+	l.storage[key] = l.queue.PushFront(&entry[K, V]{key: key, value: value})
+}
+
+func (l *lruList[K, V]) remove(key K) (*entry[K, V], bool) {
+	listItem, ok := l.storage[key]
+	if !ok {
+		return nil, false
+	}
+
+	l.queue.Remove(listItem)
+	delete(l.storage, key)
+
+	return listItem.Value, true
+}
+
+// removeOldest removes the least recently used entry.
+func (l *lruList[K, V]) removeOldest() (*entry[K, V], bool) {
+	listItem := l.queue.Back()
+	if listItem == nil {
+		return nil, false
+	}
+
+	return l.remove(listItem.Value.key)
+}
+
+// keys returns keys from the most to the least recently used.
+func (l *lruList[K, V]) keys() []K {
+	keys := make([]K, 0, l.queue.Len())
+	for item := l.queue.Front(); item != nil; item = item.Next {
+		keys = append(keys, item.Value.key)
+	}
+
+	return keys
+}
+
+func (l *lruList[K, V]) clear() {
+	l.queue.Init()
+	l.storage = make(map[K]*ListItem[*entry[K, V]])
+}
@@ -0,0 +1,313 @@
+package cache
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cacheFactory func(capacity int) Cache[Key, interface{}]
+
+func policyFactory(t *testing.T, policy Policy) cacheFactory {
+	t.Helper()
+
+	return func(capacity int) Cache[Key, interface{}] {
+		c, err := NewWithPolicy[Key, interface{}](policy, capacity)
+		require.NoError(t, err)
+
+		return c
+	}
+}
+
+func cacheFactories(t *testing.T) map[string]cacheFactory {
+	t.Helper()
+
+	factories := map[string]cacheFactory{
+		"sharded": func(capacity int) Cache[Key, interface{}] {
+			return NewShardedLRUCache(capacity, 1)
+		},
+	}
+
+	for _, policy := range []Policy{PolicyLRU, PolicyLFU, Policy2Q, PolicyARC} {
+		factories[policy.String()] = policyFactory(t, policy)
+	}
+
+	return factories
+}
+
+// TestCacheConformance checks behaviour which every Cache implementation must share.
+func TestCacheConformance(t *testing.T) {
+	for name, factory := range cacheFactories(t) {
+		factory := factory
+		t.Run(name, func(t *testing.T) {
+			testCacheConformance(t, factory)
+		})
+	}
+}
+
+func testCacheConformance(t *testing.T, newCache cacheFactory) {
+	t.Helper()
+
+	t.Run("set and get", func(t *testing.T) {
+		c := newCache(3)
+
+		assert.False(t, mustSet(t, c, "testKey", "testValue"))
+		assert.True(t, mustSet(t, c, "testKey", "newTestValue"))
+
+		value, ok := c.Get("testKey")
+
+		assert.True(t, ok)
+		assert.Equal(t, value, "newTestValue")
+	})
+
+	t.Run("get nonexistent key", func(t *testing.T) {
+		c := newCache(3)
+		value, ok := c.Get("nonexistentKey")
+
+		assert.Nil(t, value)
+		assert.False(t, ok)
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		c := newCache(3)
+
+		for i := 0; i < 10; i++ {
+			mustSet(t, c, Key(strconv.Itoa(i)), i)
+			c.Get(Key(strconv.Itoa(i % 2)))
+
+			assert.LessOrEqual(t, c.Len(), 3)
+		}
+
+		assert.Equal(t, c.Len(), 3)
+		assert.Equal(t, c.Cap(), 3)
+	})
+
+	t.Run("unlimited capacity", func(t *testing.T) {
+		c := newCache(Unlimited)
+
+		for i := 0; i < 100; i++ {
+			mustSet(t, c, Key(strconv.Itoa(i)), i)
+		}
+
+		assert.Equal(t, c.Len(), 100)
+	})
+
+	t.Run("peek and contains", func(t *testing.T) {
+		c := newCache(3)
+		mustSet(t, c, "testKey", "testValue")
+
+		value, ok := c.Peek("testKey")
+
+		assert.True(t, ok)
+		assert.Equal(t, value, "testValue")
+		assert.True(t, c.Contains("testKey"))
+		assert.False(t, c.Contains("nonexistentKey"))
+
+		_, ok = c.Peek("nonexistentKey")
+
+		assert.False(t, ok)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		c := newCache(3)
+		mustSet(t, c, "testKey1", "testValue1")
+		mustSet(t, c, "testKey2", "testValue2")
+
+		assert.True(t, c.Delete("testKey1"))
+		assert.False(t, c.Delete("testKey1"))
+		assert.False(t, c.Contains("testKey1"))
+		assert.Equal(t, c.Len(), 1)
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		c := newCache(3)
+		mustSet(t, c, "testKey1", "testValue1")
+		mustSet(t, c, "testKey2", "testValue2")
+		c.Get("testKey1")
+
+		assert.ElementsMatch(t, c.Keys(), []Key{"testKey1", "testKey2"})
+	})
+
+	t.Run("resize", func(t *testing.T) {
+		c := newCache(3)
+		mustSet(t, c, "testKey1", "testValue1")
+		mustSet(t, c, "testKey2", "testValue2")
+		mustSet(t, c, "testKey3", "testValue3")
+
+		assert.Equal(t, c.Resize(1), 2)
+		assert.Equal(t, c.Len(), 1)
+		assert.Equal(t, c.Cap(), 1)
+
+		mustSet(t, c, "testKey4", "testValue4")
+
+		assert.Equal(t, c.Len(), 1)
+	})
+
+	t.Run("resize to zero", func(t *testing.T) {
+		c := newCache(3)
+		mustSet(t, c, "testKey1", "testValue1")
+		mustSet(t, c, "testKey2", "testValue2")
+
+		assert.Equal(t, c.Resize(0), 2)
+		assert.Equal(t, c.Len(), 0)
+		assert.Equal(t, c.Cap(), 0)
+
+		// cache of zero capacity stores nothing
+		assert.False(t, mustSet(t, c, "testKey3", "testValue3"))
+		assert.Equal(t, c.Len(), 0)
+		assert.False(t, c.Contains("testKey3"))
+	})
+
+	t.Run("zero capacity", func(t *testing.T) {
+		c := newCache(0)
+		mustSet(t, c, "testKey", "testValue")
+
+		assert.Equal(t, c.Len(), 0)
+		assert.False(t, c.Contains("testKey"))
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		c := newCache(3)
+		mustSet(t, c, "testKey1", "testValue1")
+		mustSet(t, c, "testKey2", "testValue2")
+		c.Clear()
+
+		assert.Equal(t, c.Len(), 0)
+		assert.Empty(t, c.Keys())
+		assert.False(t, c.Contains("testKey1"))
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		c := newCache(16)
+		wg := sync.WaitGroup{}
+
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					key := Key(strconv.Itoa((g * i) % 32))
+					c.Set(key, i)
+					c.Get(key)
+					c.Delete(Key(strconv.Itoa(i % 32)))
+				}
+			}(g)
+		}
+
+		wg.Wait()
+
+		assert.LessOrEqual(t, c.Len(), 16)
+	})
+}
+
+func TestPolicyString(t *testing.T) {
+	assert.Equal(t, PolicyLRU.String(), "lru")
+	assert.Equal(t, PolicyLFU.String(), "lfu")
+	assert.Equal(t, Policy2Q.String(), "2q")
+	assert.Equal(t, PolicyARC.String(), "arc")
+	assert.Equal(t, Policy(100).String(), "unknown")
+}
+
+func TestNewWithPolicyUnknownPolicy(t *testing.T) {
+	c, err := NewWithPolicy[Key, interface{}](Policy(100), 10)
+
+	assert.ErrorIs(t, err, ErrUnknownPolicy)
+	assert.Nil(t, c)
+}
+
+func TestNewWithPolicyUnsupportedOptions(t *testing.T) {
+	sizer := WithSizer(func(value interface{}) int64 { return 1 })
+
+	for _, policy := range []Policy{PolicyLFU, Policy2Q, PolicyARC} {
+		c, err := NewWithPolicy[Key, interface{}](policy, 10, WithDefaultTTL(time.Minute), WithMaxCost(10), sizer)
+
+		require.ErrorIs(t, err, ErrUnsupportedOption, policy)
+		assert.Equal(t, err.Error(), "option is not supported by eviction policy: "+
+			policy.String()+" does not support WithDefaultTTL, WithMaxCost, WithSizer")
+		assert.Nil(t, c)
+
+		// options keeping defaults are accepted
+		c, err = NewWithPolicy[Key, interface{}](policy, 10, WithDefaultTTL(0), WithMaxCost(0))
+
+		assert.NoError(t, err, policy)
+		assert.NotNil(t, c)
+	}
+
+	c, err := NewWithPolicy[Key, interface{}](PolicyLRU, 10, WithDefaultTTL(time.Minute), WithMaxCost(10), sizer)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+}
+
+// scanTrace returns keys of a workload with a small hot set accessed most of the time,
+// interrupted by long scans over keys which are never accessed again.
+func scanTrace(length int) []Key {
+	rnd := rand.New(rand.NewSource(42)) //#nosec G404
+	zipf := rand.NewZipf(rnd, 1.1, 1, 999)
+	trace := make([]Key, 0, length)
+	scanned := 0
+
+	for len(trace) < length {
+		if len(trace)%10000 == 5000 {
+			for i := 0; i < 2000; i++ {
+				trace = append(trace, Key("scan"+strconv.Itoa(scanned)))
+				scanned++
+			}
+			continue
+		}
+
+		trace = append(trace, Key("hot"+strconv.FormatUint(zipf.Uint64(), 10)))
+	}
+
+	return trace[:length]
+}
+
+// loopTrace returns keys of a workload which loops over a working set slightly larger than the cache.
+func loopTrace(length int, workingSet int) []Key {
+	trace := make([]Key, length)
+	for i := range trace {
+		trace[i] = Key(strconv.Itoa(i % workingSet))
+	}
+
+	return trace
+}
+
+func benchmarkHitRatio(b *testing.B, trace []Key, capacity int) {
+	b.Helper()
+
+	for _, policy := range []Policy{PolicyLRU, PolicyLFU, Policy2Q, PolicyARC} {
+		policy := policy
+		b.Run(policy.String(), func(b *testing.B) {
+			hits, total := 0, 0
+
+			for i := 0; i < b.N; i++ {
+				c, err := NewWithPolicy[Key, interface{}](policy, capacity)
+				require.NoError(b, err)
+
+				for _, key := range trace {
+					if _, ok := c.Get(key); ok {
+						hits++
+					} else {
+						c.Set(key, struct{}{})
+					}
+					total++
+				}
+			}
+
+			b.ReportMetric(float64(hits)/float64(total)*100, "hit%")
+		})
+	}
+}
+
+func BenchmarkHitRatioScan(b *testing.B) {
+	benchmarkHitRatio(b, scanTrace(100000), 500)
+}
+
+func BenchmarkHitRatioLoop(b *testing.B) {
+	benchmarkHitRatio(b, loopTrace(100000, 600), 500)
+}
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const keysPath = "/keys/"
+
+// ServerStats is a body of /stats response of the cache server.
+type ServerStats struct {
+	Stats
+	// Cap is capacity of the cache.
+	Cap int
+}
+
+// resizeResult is a body of /resize response of the cache server.
+type resizeResult struct {
+	Evicted int
+}
+
+// Handler exposes cache over HTTP, so several processes can share it:
+//
+//	GET    /keys            returns JSON array of keys
+//	GET    /keys/{key}      returns value, with ?peek=true recency of the key is not updated
+//	HEAD   /keys/{key}      reports existence of the key without updating its recency
+//	PUT    /keys/{key}      stores request body, responds 201 for new keys and 200 for existing ones
+//	DELETE /keys/{key}      removes the key
+//	POST   /clear           removes all keys
+//	POST   /resize?cap={n}  changes capacity, returns JSON with number of evicted entries
+//	GET    /stats           returns JSON with statistics of the cache
+//
+// Client implements Cache interface on top of this protocol.
+type Handler struct {
+	cache       Cache[string, []byte]
+	maxBodySize int64
+}
+
+// HandlerOption configures optional behaviour of a Handler.
+type HandlerOption func(*Handler)
+
+// WithMaxBodySize limits size of values stored with PUT, larger values are rejected
+// with 413 status before they are read into memory.
+// Non-positive size means values are not limited, which is the default.
+func WithMaxBodySize(size int64) HandlerOption {
+	return func(h *Handler) {
+		h.maxBodySize = size
+	}
+}
+
+// NewHandler returns pointer to newly created Handler serving given cache.
+// Statistics are served only if cache implements StatsProvider.
+func NewHandler(cache Cache[string, []byte], opts ...HandlerOption) *Handler {
+	h := &Handler{cache: cache}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/keys":
+		h.serveMethods(w, r, map[string]http.HandlerFunc{http.MethodGet: h.keys})
+	case strings.HasPrefix(r.URL.Path, keysPath) && len(r.URL.Path) > len(keysPath):
+		h.serveMethods(w, r, map[string]http.HandlerFunc{
+			http.MethodGet:    h.get,
+			http.MethodHead:   h.contains,
+			http.MethodPut:    h.set,
+			http.MethodDelete: h.delete,
+		})
+	case r.URL.Path == "/clear":
+		h.serveMethods(w, r, map[string]http.HandlerFunc{http.MethodPost: h.clear})
+	case r.URL.Path == "/resize":
+		h.serveMethods(w, r, map[string]http.HandlerFunc{http.MethodPost: h.resize})
+	case r.URL.Path == "/stats":
+		h.serveMethods(w, r, map[string]http.HandlerFunc{http.MethodGet: h.stats})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveMethods calls handler registered for method of the request.
+func (h *Handler) serveMethods(w http.ResponseWriter, r *http.Request, handlers map[string]http.HandlerFunc) {
+	handler, ok := handlers[r.Method]
+	if !ok {
+		methods := make([]string, 0, len(handlers))
+		for method := range handlers {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	handler(w, r)
+}
+
+func (h *Handler) keys(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, h.cache.Keys())
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, keysPath)
+
+	var value []byte
+	var ok bool
+	if peek, _ := strconv.ParseBool(r.URL.Query().Get("peek")); peek {
+		value, ok = h.cache.Peek(key)
+	} else {
+		value, ok = h.cache.Get(key)
+	}
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	// nothing can be done if client went away
+	_, _ = w.Write(value)
+}
+
+func (h *Handler) contains(w http.ResponseWriter, r *http.Request) {
+	if !h.cache.Contains(strings.TrimPrefix(r.URL.Path, keysPath)) {
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (h *Handler) set(w http.ResponseWriter, r *http.Request) {
+	body := r.Body
+	if h.maxBodySize > 0 {
+		body = http.MaxBytesReader(w, body, h.maxBodySize)
+	}
+
+	value, err := io.ReadAll(body)
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		http.Error(w, fmt.Sprintf("%s: size limit %d", ErrTooLarge, tooLarge.Limit), http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existed, err := h.cache.Set(strings.TrimPrefix(r.URL.Path, keysPath), value)
+	switch {
+	case errors.Is(err, ErrTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	case existed:
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	if !h.cache.Delete(strings.TrimPrefix(r.URL.Path, keysPath)) {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) clear(w http.ResponseWriter, _ *http.Request) {
+	h.cache.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) resize(w http.ResponseWriter, r *http.Request) {
+	capacity, err := strconv.Atoi(r.URL.Query().Get("cap"))
+	if err != nil {
+		http.Error(w, "invalid capacity: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, resizeResult{Evicted: h.cache.Resize(capacity)})
+}
+
+func (h *Handler) stats(w http.ResponseWriter, _ *http.Request) {
+	provider, ok := h.cache.(StatsProvider)
+	if !ok {
+		http.Error(w, "cache does not provide statistics", http.StatusNotImplemented)
+		return
+	}
+
+	writeJSON(w, ServerStats{Stats: provider.Stats(), Cap: h.cache.Cap()})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	// nothing can be done if client went away
+	_ = json.NewEncoder(w).Encode(v)
+}
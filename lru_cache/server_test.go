@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerRejectsUnknownRequests(t *testing.T) {
+	handler := NewHandler(New[string, []byte](5))
+
+	tests := []struct {
+		method string
+		target string
+		status int
+		allow  string
+	}{
+		{method: http.MethodGet, target: "/unknown", status: http.StatusNotFound},
+		{method: http.MethodGet, target: "/keys/", status: http.StatusNotFound},
+		{
+			method: http.MethodPost, target: "/keys/testKey",
+			status: http.StatusMethodNotAllowed, allow: "DELETE, GET, HEAD, PUT",
+		},
+		{method: http.MethodGet, target: "/clear", status: http.StatusMethodNotAllowed, allow: "POST"},
+		{method: http.MethodPost, target: "/resize?cap=abc", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader("")))
+
+		assert.Equal(t, rec.Code, tt.status, tt.method+" "+tt.target)
+		assert.Equal(t, rec.Header().Get("Allow"), tt.allow, tt.method+" "+tt.target)
+	}
+}
+
+func TestHandlerLimitsBodySize(t *testing.T) {
+	c := New[string, []byte](5)
+	handler := NewHandler(c, WithMaxBodySize(4))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/keys/testKey", strings.NewReader("testValue")))
+
+	assert.Equal(t, rec.Code, http.StatusRequestEntityTooLarge)
+	assert.Contains(t, rec.Body.String(), ErrTooLarge.Error())
+	assert.False(t, c.Contains("testKey"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/keys/testKey", strings.NewReader("test")))
+
+	assert.Equal(t, rec.Code, http.StatusCreated)
+}
+
+func TestHandlerStatsNotImplemented(t *testing.T) {
+	// embedding hides Stats method of the cache
+	c := newTestServer(t, struct{ Cache[string, []byte] }{New[string, []byte](5)})
+
+	_, err := c.ServerStats()
+
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+}
@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+)
+
+var _ Cache[Key, interface{}] = (*ShardedLRUCache[Key, interface{}])(nil)
+
+// ShardedLRUCache is a goroutine-safe cache which splits keys between independent LRU shards.
+// Every shard is a separate LRUCache with its own lock and its own queue, so operations on keys
+// from different shards do not contend. LRU order is maintained per shard.
+//
+// Keys are listed in recency order within each shard, but not across shards.
+type ShardedLRUCache[K comparable, V any] struct {
+	shards []*LRUCache[K, V]
+	hash   func(K) uint32
+}
+
+// HashString returns FNV-1a hash of a string-like key.
+// It can be used as a hash function for NewSharded.
+func HashString[K ~string](key K) uint32 {
+	h := fnv.New32a()
+	// hash.Hash never returns an error on Write
+	_, _ = h.Write([]byte(key))
+
+	return h.Sum32()
+}
+
+// NewSharded returns pointer to newly created ShardedLRUCache with given total capacity
+// spread over given number of shards. Keys are assigned to shards with given hash function.
+// Each shard holds at least one element. Options are applied to every shard.
+//
+// Maximum cost set with WithMaxCost is split between shards, so their budgets add up to it,
+// unless it is less than number of shards, in which case budget of every shard is one.
+// Every shard enforces its own budget, so an item which does not fit into budget of its shard,
+// about maximum cost divided by number of shards, is rejected with ErrTooLarge.
+func NewSharded[K comparable, V any](
+	capacity int, shards int, hash func(K) uint32, opts ...Option,
+) *ShardedLRUCache[K, V] {
+	if shards < 1 {
+		shards = 1
+	}
+
+	perShard := shardCapacity(capacity, shards)
+	maxCost := newOptions(opts).maxCost
+
+	c := &ShardedLRUCache[K, V]{
+		shards: make([]*LRUCache[K, V], shards),
+		hash:   hash,
+	}
+
+	for i := range c.shards {
+		shardOpts := opts
+		if maxCost > 0 {
+			// copy options, so caller's slice is not modified
+			shardOpts = append(opts[:len(opts):len(opts)], WithMaxCost(shardCost(maxCost, shards, i)))
+		}
+
+		c.shards[i] = New[K, V](perShard, shardOpts...)
+	}
+
+	return c
+}
+
+// NewShardedLRUCache returns pointer to newly created ShardedLRUCache with given total capacity
+// spread over given number of shards. Each shard holds at least one element.
+func NewShardedLRUCache(capacity int, shards int, opts ...Option) *ShardedLRUCache[Key, interface{}] {
+	return NewSharded[Key, interface{}](capacity, shards, HashString[Key], opts...)
+}
+
+func shardCapacity(capacity int, shards int) int {
+	// keep number of entries unlimited
+	if capacity == Unlimited {
+		return Unlimited
+	}
+	if capacity <= 0 {
+		return 0
+	}
+
+	// round up, so total capacity is not less than requested
+	return (capacity + shards - 1) / shards
+}
+
+// shardCost returns budget of shard with given index, so budgets of all shards add up to given cost.
+func shardCost(cost int64, shards int, shard int) int64 {
+	perShard := cost / int64(shards)
+	if int64(shard) < cost%int64(shards) {
+		perShard++
+	}
+
+	// zero budget would mean no limit
+	return max(perShard, 1)
+}
+
+func (c *ShardedLRUCache[K, V]) getShard(key K) *LRUCache[K, V] {
+	return c.shards[c.hash(key)%uint32(len(c.shards))]
+}
+
+// Set stores key with given value in the shard owning the key.
+// Sets return boolean indicating existence of a given key in the cache.
+func (c *ShardedLRUCache[K, V]) Set(key K, value V) (bool, error) {
+	return c.getShard(key).Set(key, value)
+}
+
+// Get returns value associated with a given key in the cache and
+// boolean indicating existence of key in the cache.
+func (c *ShardedLRUCache[K, V]) Get(key K) (V, bool) {
+	return c.getShard(key).Get(key)
+}
+
+// GetOrLoad returns value associated with a given key in the shard owning the key,
+// loading it with given loader if the key is missing.
+func (c *ShardedLRUCache[K, V]) GetOrLoad(ctx context.Context, key K, loader LoaderFunc[V]) (V, error) {
+	return c.getShard(key).GetOrLoad(ctx, key, loader)
+}
+
+// Peek returns value associated with a given key in the cache and
+// boolean indicating existence of key in the cache without updating its recency.
+func (c *ShardedLRUCache[K, V]) Peek(key K) (V, bool) {
+	return c.getShard(key).Peek(key)
+}
+
+// Contains reports whether given key exists in the cache without updating its recency.
+func (c *ShardedLRUCache[K, V]) Contains(key K) bool {
+	return c.getShard(key).Contains(key)
+}
+
+// Len returns total number of entries in all shards of the cache.
+func (c *ShardedLRUCache[K, V]) Len() int {
+	length := 0
+	for _, s := range c.shards {
+		length += s.Len()
+	}
+
+	return length
+}
+
+// Cap returns total capacity of all shards of the cache.
+func (c *ShardedLRUCache[K, V]) Cap() int {
+	capacity := 0
+	for _, s := range c.shards {
+		// sum of unlimited capacities would overflow
+		if s.Cap() == Unlimited {
+			return Unlimited
+		}
+		capacity += s.Cap()
+	}
+
+	return capacity
+}
+
+// Keys returns keys of not expired entries of all shards.
+// Keys of each shard are ordered from the most to the least recently used.
+func (c *ShardedLRUCache[K, V]) Keys() []K {
+	var keys []K
+	for _, s := range c.shards {
+		keys = append(keys, s.Keys()...)
+	}
+
+	return keys
+}
+
+// Resize spreads new capacity over shards, evicting the least recently used
+// entries of every shard which do not fit into it. Resize returns number of evicted entries.
+func (c *ShardedLRUCache[K, V]) Resize(capacity int) int {
+	perShard := shardCapacity(capacity, len(c.shards))
+
+	evicted := 0
+	for _, s := range c.shards {
+		evicted += s.Resize(perShard)
+	}
+
+	return evicted
+}
+
+// Delete removes given key from the shard owning the key.
+// Delete returns boolean indicating existence of a given key in the cache.
+func (c *ShardedLRUCache[K, V]) Delete(key K) bool {
+	return c.getShard(key).Delete(key)
+}
+
+// Clear removes all data in every shard of the cache.
+func (c *ShardedLRUCache[K, V]) Clear() {
+	for _, s := range c.shards {
+		s.Clear()
+	}
+}
+
+// SetWithTTL stores key with given value, which expires after given ttl, in the shard owning the key.
+// SetWithTTL returns boolean indicating existence of a given key in the cache.
+func (c *ShardedLRUCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) (bool, error) {
+	return c.getShard(key).SetWithTTL(key, value, ttl)
+}
+
+// SetWithCost stores key with given value and cost in the shard owning the key.
+// SetWithCost returns ErrTooLarge if cost exceeds maximum cost of the shard.
+func (c *ShardedLRUCache[K, V]) SetWithCost(key K, value V, cost int64) (bool, error) {
+	return c.getShard(key).SetWithCost(key, value, cost)
+}
+
+// Stats returns snapshot of the cache state summed over all shards.
+func (c *ShardedLRUCache[K, V]) Stats() Stats {
+	stats := Stats{}
+	for _, s := range c.shards {
+		stats = stats.add(s.Stats())
+	}
+
+	return stats
+}
+
+// DeleteExpired removes all expired entries from every shard of the cache.
+func (c *ShardedLRUCache[K, V]) DeleteExpired() {
+	for _, s := range c.shards {
+		s.DeleteExpired()
+	}
+}
+
+// OnEvict registers function called for every entry leaving any shard of the cache.
+func (c *ShardedLRUCache[K, V]) OnEvict(fn EvictFunc[K, V]) {
+	for _, s := range c.shards {
+		s.OnEvict(fn)
+	}
+}
+
+// Stop stops background janitors of all shards.
+func (c *ShardedLRUCache[K, V]) Stop() {
+	for _, s := range c.shards {
+		s.Stop()
+	}
+}
@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedLRUCacheSetAndGet(t *testing.T) {
+	c := NewShardedLRUCache(100, 8)
+
+	assert.False(t, mustSet(t, c, "testKey", "testValue"))
+	assert.True(t, mustSet(t, c, "testKey", "newTestValue"))
+
+	value, ok := c.Get("testKey")
+
+	assert.Equal(t, value, "newTestValue")
+	assert.True(t, ok)
+}
+
+func TestShardedLRUCacheGetNonexistentKey(t *testing.T) {
+	c := NewShardedLRUCache(100, 8)
+	value, ok := c.Get("nonexistentKey")
+
+	assert.Nil(t, value)
+	assert.False(t, ok)
+}
+
+func TestShardedLRUCacheSingleShardKeepsLRUOrder(t *testing.T) {
+	c := NewShardedLRUCache(2, 1)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Get("testKey1")
+	c.Set("testKey3", "testValue3")
+
+	_, ok := c.Get("testKey2")
+	assert.False(t, ok)
+
+	_, ok = c.Get("testKey1")
+	assert.True(t, ok)
+}
+
+func TestShardedLRUCacheCapacityIsSpreadOverShards(t *testing.T) {
+	c := NewShardedLRUCache(10, 4)
+
+	assert.Len(t, c.shards, 4)
+
+	for _, s := range c.shards {
+		assert.Equal(t, s.capacity, 3)
+	}
+}
+
+func TestShardedLRUCacheGenericKeys(t *testing.T) {
+	c := NewSharded[int, string](100, 8, func(key int) uint32 { return uint32(key) })
+	c.Set(1, "one")
+	c.Set(9, "nine")
+
+	value, ok := c.Get(9)
+
+	assert.True(t, ok)
+	assert.Equal(t, value, "nine")
+	// keys 1 and 9 share the same shard
+	assert.Same(t, c.getShard(1), c.getShard(9))
+}
+
+func TestShardedLRUCacheInvalidShardsCount(t *testing.T) {
+	c := NewShardedLRUCache(0, 0)
+
+	assert.Len(t, c.shards, 1)
+	assert.Equal(t, c.shards[0].capacity, 0)
+}
+
+func TestShardedLRUCacheUnlimitedCapacity(t *testing.T) {
+	c := NewShardedLRUCache(Unlimited, 4)
+
+	for _, s := range c.shards {
+		assert.Equal(t, s.Cap(), Unlimited)
+	}
+	assert.Equal(t, c.Cap(), Unlimited)
+
+	assert.Equal(t, c.Resize(0), 0)
+	assert.Equal(t, c.Cap(), 0)
+}
+
+func TestShardedLRUCacheClear(t *testing.T) {
+	c := NewShardedLRUCache(100, 8)
+
+	for i := 0; i < 50; i++ {
+		c.Set(Key(strconv.Itoa(i)), i)
+	}
+
+	c.Clear()
+
+	for i := 0; i < 50; i++ {
+		_, ok := c.Get(Key(strconv.Itoa(i)))
+		assert.False(t, ok)
+	}
+}
+
+// Run with -race flag to detect unsynchronized access.
+func TestShardedLRUCacheConcurrentAccess(t *testing.T) {
+	c := NewShardedLRUCache(64, 8)
+	wg := sync.WaitGroup{}
+
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := Key(strconv.Itoa((g * i) % 128))
+				c.Set(key, i)
+				c.Get(key)
+
+				if i%100 == 0 {
+					c.Clear()
+				}
+			}
+		}(g)
+	}
+
+	wg.Wait()
+}
+
+// BenchmarkLRUCacheParallel measures a single cache guarded by its own mutex for comparison with sharding.
+func BenchmarkLRUCacheParallel(b *testing.B) {
+	c := NewLRUCache(1024)
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := Key(strconv.Itoa(i % 2048))
+			if _, ok := c.Get(key); !ok {
+				c.Set(key, i)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkShardedLRUCacheParallel(b *testing.B) {
+	c := NewShardedLRUCache(1024, 32)
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := Key(strconv.Itoa(i % 2048))
+			if _, ok := c.Get(key); !ok {
+				c.Set(key, i)
+			}
+			i++
+		}
+	})
+}
+
+func TestShardedLRUCacheSetWithTTL(t *testing.T) {
+	clock := newFakeClock()
+	c := NewShardedLRUCache(100, 8, WithClock(clock))
+	defer c.Stop()
+
+	c.SetWithTTL("testKey1", "testValue1", time.Minute)
+	c.SetWithTTL("testKey2", "testValue2", time.Hour)
+
+	clock.Advance(time.Minute)
+	c.DeleteExpired()
+
+	_, ok := c.Get("testKey1")
+	assert.False(t, ok)
+
+	_, ok = c.Get("testKey2")
+	assert.True(t, ok)
+}
+
+func TestShardedLRUCacheCRUD(t *testing.T) {
+	c := NewShardedLRUCache(8, 4)
+
+	for i := 0; i < 4; i++ {
+		c.Set(Key(strconv.Itoa(i)), i)
+	}
+
+	assert.Equal(t, c.Cap(), 8)
+	assert.Equal(t, c.Len(), 4)
+	assert.ElementsMatch(t, c.Keys(), []Key{"0", "1", "2", "3"})
+	assert.True(t, c.Contains("1"))
+
+	value, ok := c.Peek("2")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, 2)
+
+	assert.True(t, c.Delete("1"))
+	assert.False(t, c.Contains("1"))
+	assert.Equal(t, c.Len(), 3)
+}
+
+func TestShardedLRUCacheResize(t *testing.T) {
+	c := NewShardedLRUCache(8, 1)
+
+	for i := 0; i < 8; i++ {
+		c.Set(Key(strconv.Itoa(i)), i)
+	}
+
+	assert.Equal(t, c.Resize(4), 4)
+	assert.Equal(t, c.Cap(), 4)
+	assert.Equal(t, c.Keys(), []Key{"7", "6", "5", "4"})
+}
+
+func TestShardedLRUCacheMaxCostIsSpreadOverShards(t *testing.T) {
+	c := NewShardedLRUCache(Unlimited, 4, WithMaxCost(10))
+
+	budgets := make([]int64, 0, len(c.shards))
+	for _, s := range c.shards {
+		budgets = append(budgets, s.maxCost)
+	}
+
+	// budgets add up to the requested one
+	assert.Equal(t, budgets, []int64{3, 3, 2, 2})
+
+	_, err := c.SetWithCost("testKey", "testValue", 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, sizeStats(c.Stats()), Stats{Len: 1, Cost: 2, MaxCost: 10})
+
+	// item fitting the whole budget is rejected if it does not fit budget of its shard
+	_, err = c.SetWithCost("testKey", "testValue", 4)
+
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestShardedLRUCacheMaxCostLessThanShards(t *testing.T) {
+	c := NewShardedLRUCache(Unlimited, 4, WithMaxCost(2))
+
+	for _, s := range c.shards {
+		assert.Equal(t, s.maxCost, int64(1))
+	}
+}
@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	ErrInvalidSnapshot      = errors.New("invalid cache snapshot")
+	ErrIncompatibleSnapshot = errors.New("incompatible cache snapshot")
+)
+
+const (
+	snapshotMagic   = "LRUSNAP"
+	snapshotVersion = 1
+)
+
+// Encoder writes values to underlying stream.
+type Encoder interface {
+	Encode(v interface{}) error
+}
+
+// Decoder reads values from underlying stream.
+// It returns io.EOF when there are no more values.
+type Decoder interface {
+	Decode(v interface{}) error
+}
+
+// Codec serializes cache entries in snapshots.
+// Name of the codec is stored in snapshot header, so snapshot can be restored
+// only with the codec it was written with.
+type Codec interface {
+	Name() string
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+// GobCodec serializes entries with encoding/gob. It is the default codec.
+// Concrete types stored in interface values must be registered with gob.Register.
+type GobCodec struct{}
+
+func (GobCodec) Name() string {
+	return "gob"
+}
+
+func (GobCodec) NewEncoder(w io.Writer) Encoder {
+	return gob.NewEncoder(w)
+}
+
+func (GobCodec) NewDecoder(r io.Reader) Decoder {
+	return gob.NewDecoder(r)
+}
+
+// JSONCodec serializes entries with encoding/json.
+// Interface values are restored as types produced by json.Unmarshal.
+type JSONCodec struct{}
+
+func (JSONCodec) Name() string {
+	return "json"
+}
+
+func (JSONCodec) NewEncoder(w io.Writer) Encoder {
+	return json.NewEncoder(w)
+}
+
+func (JSONCodec) NewDecoder(r io.Reader) Decoder {
+	return json.NewDecoder(r)
+}
+
+// snapshotEntry is a serialized form of a cache entry.
+type snapshotEntry[K comparable, V any] struct {
+	Key       K
+	Value     V
+	ExpiresAt time.Time
+	Cost      int64
+}
+
+// Snapshot writes not expired entries of the cache to given writer
+// from the most to the least recently used. Nil codec means GobCodec.
+func (c *LRUCache[K, V]) Snapshot(w io.Writer, codec Codec) error {
+	if codec == nil {
+		codec = GobCodec{}
+	}
+
+	c.mu.Lock()
+	now := c.clock.Now()
+	entries := make([]snapshotEntry[K, V], 0, c.queue.Len())
+	for i := c.queue.Front(); i != 0; i = c.queue.Next(i) {
+		item := c.queue.Value(i)
+		if item.expired(now) {
+			continue
+		}
+		entries = append(entries, snapshotEntry[K, V]{
+			Key:       item.key,
+			Value:     item.value,
+			ExpiresAt: item.expiresAt,
+			Cost:      item.cost,
+		})
+	}
+	c.mu.Unlock()
+
+	buf := bufio.NewWriter(w)
+
+	if err := writeSnapshotHeader(buf, codec.Name()); err != nil {
+		return fmt.Errorf("failed to write snapshot header: %w", err)
+	}
+
+	encoder := codec.NewEncoder(buf)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to encode entry: %w", err)
+		}
+	}
+
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// Restore replaces contents of the cache with entries read from given reader,
+// preserving their recency order. Entries which expired since the snapshot was taken
+// are skipped, entries which do not fit into the cache are evicted as usual.
+// Costs of entries are computed by sizer of the cache, costs from the snapshot are kept if it has none.
+// Nil codec means GobCodec. Current contents are kept if snapshot can not be read.
+func (c *LRUCache[K, V]) Restore(r io.Reader, codec Codec) error {
+	if codec == nil {
+		codec = GobCodec{}
+	}
+
+	buf := bufio.NewReader(r)
+
+	if err := readSnapshotHeader(buf, codec.Name()); err != nil {
+		return err
+	}
+
+	var entries []snapshotEntry[K, V]
+	decoder := codec.NewDecoder(buf)
+	for {
+		var entry snapshotEntry[K, V]
+		err := decoder.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	c.loads.clearFailures()
+
+	// contents are replaced under a single lock, so concurrent writes are not mixed into them
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.clear()
+	now := c.clock.Now()
+
+	// the least recently used entry goes first, so the most recent one ends in front
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if !entry.ExpiresAt.IsZero() && !now.Before(entry.ExpiresAt) {
+			continue
+		}
+		// cost is computed by sizer of this cache if it has one, since snapshot may come from another cache
+		cost := entry.Cost
+		if c.sizer != nil {
+			cost = c.costOf(entry.Value)
+		}
+		// entries which are too large for this cache are skipped
+		_, _ = c.setUntil(entry.Key, entry.Value, entry.ExpiresAt, cost)
+	}
+
+	return nil
+}
+
+func writeSnapshotHeader(w io.Writer, codecName string) error {
+	header := make([]byte, 0, len(snapshotMagic)+2+len(codecName))
+	header = append(header, snapshotMagic...)
+	header = append(header, snapshotVersion, byte(len(codecName)))
+	header = append(header, codecName...)
+
+	_, err := w.Write(header)
+
+	return err
+}
+
+func readSnapshotHeader(r io.Reader, codecName string) error {
+	header := make([]byte, len(snapshotMagic)+2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("%w: failed to read header: %w", ErrInvalidSnapshot, err)
+	}
+
+	if string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return fmt.Errorf("%w: unknown format", ErrInvalidSnapshot)
+	}
+
+	if version := header[len(snapshotMagic)]; version != snapshotVersion {
+		return fmt.Errorf("%w: version %d, supported version %d", ErrIncompatibleSnapshot, version, snapshotVersion)
+	}
+
+	name := make([]byte, header[len(snapshotMagic)+1])
+	if _, err := io.ReadFull(r, name); err != nil {
+		return fmt.Errorf("%w: failed to read codec name: %w", ErrInvalidSnapshot, err)
+	}
+
+	if string(name) != codecName {
+		return fmt.Errorf("%w: written with codec %q, restoring with %q", ErrIncompatibleSnapshot, name, codecName)
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package cache
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotAndRestore(t *testing.T) {
+	for _, codec := range []Codec{GobCodec{}, JSONCodec{}} {
+		codec := codec
+		t.Run(codec.Name(), func(t *testing.T) {
+			c := New[Key, string](5)
+			c.Set("testKey1", "testValue1")
+			c.Set("testKey2", "testValue2")
+			c.Set("testKey3", "testValue3")
+			c.Get("testKey1")
+
+			buf := bytes.Buffer{}
+			require.NoError(t, c.Snapshot(&buf, codec))
+
+			restored := New[Key, string](5)
+			restored.Set("oldKey", "oldValue")
+			require.NoError(t, restored.Restore(&buf, codec))
+
+			assert.Equal(t, restored.Keys(), []Key{"testKey1", "testKey3", "testKey2"})
+
+			value, ok := restored.Get("testKey2")
+
+			assert.True(t, ok)
+			assert.Equal(t, value, "testValue2")
+		})
+	}
+}
+
+func TestSnapshotDefaultCodec(t *testing.T) {
+	c := New[int, []byte](5)
+	c.Set(1, []byte("one"))
+
+	buf := bytes.Buffer{}
+	require.NoError(t, c.Snapshot(&buf, nil))
+
+	restored := New[int, []byte](5)
+	require.NoError(t, restored.Restore(&buf, GobCodec{}))
+
+	value, ok := restored.Get(1)
+
+	assert.True(t, ok)
+	assert.Equal(t, value, []byte("one"))
+}
+
+func TestRestoreSkipsExpiredEntries(t *testing.T) {
+	clock := newFakeClock()
+	c := New[Key, string](5, WithClock(clock))
+	c.SetWithTTL("testKey1", "testValue1", time.Minute)
+	c.SetWithTTL("testKey2", "testValue2", time.Hour)
+	c.Set("testKey3", "testValue3")
+
+	buf := bytes.Buffer{}
+	require.NoError(t, c.Snapshot(&buf, nil))
+
+	clock.Advance(time.Minute)
+
+	restored := New[Key, string](5, WithClock(clock))
+	require.NoError(t, restored.Restore(&buf, nil))
+
+	assert.Equal(t, restored.Keys(), []Key{"testKey3", "testKey2"})
+
+	// remaining time to live is preserved
+	clock.Advance(59 * time.Minute)
+
+	assert.False(t, restored.Contains("testKey2"))
+	assert.True(t, restored.Contains("testKey3"))
+}
+
+func TestSnapshotSkipsExpiredEntries(t *testing.T) {
+	clock := newFakeClock()
+	c := New[Key, string](5, WithClock(clock))
+	c.SetWithTTL("testKey1", "testValue1", time.Minute)
+	c.Set("testKey2", "testValue2")
+	clock.Advance(time.Minute)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, c.Snapshot(&buf, nil))
+
+	restored := New[Key, string](5, WithClock(clock))
+	require.NoError(t, restored.Restore(&buf, nil))
+
+	assert.Equal(t, restored.Keys(), []Key{"testKey2"})
+}
+
+func TestRestoreIntoSmallerCacheKeepsMostRecent(t *testing.T) {
+	c := New[Key, string](5)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+	c.Set("testKey3", "testValue3")
+
+	buf := bytes.Buffer{}
+	require.NoError(t, c.Snapshot(&buf, nil))
+
+	restored := New[Key, string](2)
+	require.NoError(t, restored.Restore(&buf, nil))
+
+	assert.Equal(t, restored.Keys(), []Key{"testKey3", "testKey2"})
+}
+
+func TestRestorePreservesCost(t *testing.T) {
+	c := New[Key, string](Unlimited, WithMaxCost(10))
+	c.SetWithCost("testKey", "testValue", 7)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, c.Snapshot(&buf, nil))
+
+	restored := New[Key, string](Unlimited, WithMaxCost(10))
+	require.NoError(t, restored.Restore(&buf, nil))
+
+	assert.Equal(t, restored.Stats().Cost, int64(7))
+}
+
+func TestRestoreReplacesContentsAtOnce(t *testing.T) {
+	c := New[Key, string](5)
+	c.Set("testKey1", "testValue1")
+
+	buf := bytes.Buffer{}
+	require.NoError(t, c.Snapshot(&buf, nil))
+
+	restored := New[Key, string](5)
+	restored.Set("testKey2", "testValue2")
+
+	var cleared []Key
+	var keys [][]Key
+	restored.OnEvict(func(key Key, value string, reason EvictReason) {
+		assert.Equal(t, reason, EvictCleared)
+		cleared = append(cleared, key)
+		// callbacks run after the whole restore, so the cache is never seen empty
+		keys = append(keys, restored.Keys())
+	})
+
+	require.NoError(t, restored.Restore(&buf, nil))
+
+	assert.Equal(t, cleared, []Key{"testKey2"})
+	assert.Equal(t, keys, [][]Key{{"testKey1"}})
+}
+
+func TestRestoreRecomputesCostWithSizer(t *testing.T) {
+	c := New[Key, string](Unlimited)
+	for i := 0; i < 10; i++ {
+		c.Set(Key(strconv.Itoa(i)), strings.Repeat("x", 100))
+	}
+
+	buf := bytes.Buffer{}
+	require.NoError(t, c.Snapshot(&buf, nil))
+
+	sizer := func(value string) int64 { return int64(len(value)) }
+	restored := New[Key, string](Unlimited, WithMaxCost(250), WithSizer(sizer))
+	require.NoError(t, restored.Restore(&buf, nil))
+
+	// the most recent entries which fit the budget are kept
+	assert.Equal(t, restored.Keys(), []Key{"9", "8"})
+	assert.Equal(t, restored.Stats().Cost, int64(200))
+}
+
+func TestRestoreWithAnotherCodec(t *testing.T) {
+	c := New[Key, string](5)
+	c.Set("testKey", "testValue")
+
+	buf := bytes.Buffer{}
+	require.NoError(t, c.Snapshot(&buf, JSONCodec{}))
+
+	restored := New[Key, string](5)
+	restored.Set("oldKey", "oldValue")
+	err := restored.Restore(&buf, GobCodec{})
+
+	assert.ErrorIs(t, err, ErrIncompatibleSnapshot)
+	// contents are kept on error
+	assert.True(t, restored.Contains("oldKey"))
+}
+
+func TestRestoreUnsupportedVersion(t *testing.T) {
+	buf := bytes.NewBufferString(snapshotMagic + "\x02\x03gob")
+
+	err := New[Key, string](5).Restore(buf, nil)
+
+	assert.ErrorIs(t, err, ErrIncompatibleSnapshot)
+}
+
+func TestRestoreInvalidSnapshot(t *testing.T) {
+	for _, data := range []string{"", "LRU", "NOTSNAP\x01\x03gob", snapshotMagic + "\x01\x05go"} {
+		err := New[Key, string](5).Restore(bytes.NewBufferString(data), nil)
+
+		assert.ErrorIs(t, err, ErrInvalidSnapshot, "data %q", data)
+	}
+}
+
+func TestRestoreCorruptedEntries(t *testing.T) {
+	buf := bytes.Buffer{}
+	require.NoError(t, writeSnapshotHeader(&buf, "json"))
+	buf.WriteString("{not json")
+
+	err := New[Key, string](5).Restore(&buf, JSONCodec{})
+
+	assert.Error(t, err)
+}
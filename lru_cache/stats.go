@@ -0,0 +1,66 @@
+package cache
+
+import "sync/atomic"
+
+// Stats is a snapshot of the cache state.
+type Stats struct {
+	// Len is number of entries in the cache.
+	Len int
+	// Cost is total cost of entries in the cache.
+	Cost int64
+	// MaxCost is maximum total cost of entries, zero if not limited.
+	MaxCost int64
+	// Hits is number of Get calls which found a value.
+	Hits uint64
+	// Misses is number of Get calls which did not find a value.
+	Misses uint64
+	// Sets is number of successfully stored values.
+	Sets uint64
+	// Evictions is number of entries evicted to make room for other ones.
+	Evictions uint64
+	// Expirations is number of entries removed because of expired time to live.
+	Expirations uint64
+}
+
+// HitRatio returns ratio of hits to all Get calls, or zero if there were no calls.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+// add returns sum of two snapshots.
+func (s Stats) add(other Stats) Stats {
+	return Stats{
+		Len:         s.Len + other.Len,
+		Cost:        s.Cost + other.Cost,
+		MaxCost:     s.MaxCost + other.MaxCost,
+		Hits:        s.Hits + other.Hits,
+		Misses:      s.Misses + other.Misses,
+		Sets:        s.Sets + other.Sets,
+		Evictions:   s.Evictions + other.Evictions,
+		Expirations: s.Expirations + other.Expirations,
+	}
+}
+
+// counters holds cache statistics, which are updated atomically,
+// so they can be read without taking cache lock.
+type counters struct {
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	sets        atomic.Uint64
+	evictions   atomic.Uint64
+	expirations atomic.Uint64
+}
+
+// fill copies current counter values into given snapshot.
+func (c *counters) fill(s *Stats) {
+	s.Hits = c.hits.Load()
+	s.Misses = c.misses.Load()
+	s.Sets = c.sets.Load()
+	s.Evictions = c.evictions.Load()
+	s.Expirations = c.expirations.Load()
+}
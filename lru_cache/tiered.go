@@ -0,0 +1,307 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+var _ Cache[Key, interface{}] = (*TieredCache[Key, interface{}])(nil)
+
+// TieredCache is a two level cache. The first level is LRUCache keeping hot entries in memory,
+// entries evicted from it for lack of capacity are spilled to the second level stored in a file.
+// Get of an entry found on disk promotes it back to memory.
+// It implements Cache interface. It is safe for concurrent use.
+//
+// An entry lives in one of the levels at a time, so Len counts entries of both levels,
+// while capacity limits the memory level only.
+type TieredCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	clock Clock
+	l1    *LRUCache[K, V]
+	l2    *diskStore[K, V]
+	// diskErr is the first error of the disk level which was not reported yet
+	diskErr error
+}
+
+// NewTiered returns pointer to newly created TieredCache keeping given number of entries in memory
+// and the rest in the file with given path. Entries left in the file by previous run are available.
+// Options configure the memory level, while WithCodec and WithCompactionThreshold configure the file.
+func NewTiered[K comparable, V any](capacity int, path string, opts ...Option) (*TieredCache[K, V], error) {
+	o := newOptions(opts)
+
+	l2, err := openDiskStore[K, V](path, o)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TieredCache[K, V]{
+		clock: o.clock,
+		l1:    New[K, V](capacity, opts...),
+		l2:    l2,
+	}
+	t.l1.spill = t.spill
+
+	return t, nil
+}
+
+// Set stores key with given value in memory, or on disk if capacity of memory level is zero.
+// Set returns boolean indicating existence of a given key in the cache
+// and error if an entry evicted from memory could not be written to disk, see DiskErr.
+func (t *TieredCache[K, V]) Set(key K, value V) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.l1.Cap() == 0 {
+		onDisk := t.l2.contains(key)
+		// memory of zero capacity spills the entry to disk at once, replacing the one there
+		if _, err := t.l1.Set(key, value); err != nil {
+			return false, err
+		}
+
+		return onDisk, t.takeDiskErr()
+	}
+
+	existed, err := t.l1.Set(key, value)
+	if err != nil {
+		return false, err
+	}
+
+	onDisk, err := t.l2.delete(key)
+	if err != nil {
+		return existed, err
+	}
+
+	return existed || onDisk, t.takeDiskErr()
+}
+
+// Get returns value associated with a given key in the cache and
+// boolean indicating existence of key in the cache.
+// Entry found on disk is moved to memory, which may spill another entry to disk.
+// Entry which could not be read from disk is reported as missing, the error is reported by DiskErr,
+// as well as error of writing spilled entry.
+func (t *TieredCache[K, V]) Get(key K) (V, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if value, ok := t.l1.Get(key); ok {
+		return value, true
+	}
+
+	var zero V
+	value, expiresAt, ok, err := t.l2.get(key)
+	if err != nil {
+		t.diskFailed(err)
+		return zero, false
+	}
+	if !ok {
+		return zero, false
+	}
+
+	if !t.promote(key, value, expiresAt) {
+		// entry does not fit into memory, so it stays on disk
+		return value, true
+	}
+
+	// entry is in memory already, failed tombstone only leaves stale copy on disk
+	_, _ = t.l2.delete(key)
+
+	return value, true
+}
+
+// Peek returns value associated with a given key in the cache and
+// boolean indicating existence of key in the cache without updating its recency.
+// Entry found on disk is not moved to memory. Error of reading it is reported by DiskErr.
+func (t *TieredCache[K, V]) Peek(key K) (V, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if value, ok := t.l1.Peek(key); ok {
+		return value, true
+	}
+
+	var zero V
+	value, _, ok, err := t.l2.get(key)
+	if err != nil {
+		t.diskFailed(err)
+		return zero, false
+	}
+	if !ok {
+		return zero, false
+	}
+
+	return value, true
+}
+
+// Contains reports whether given key exists in the cache without updating its recency.
+func (t *TieredCache[K, V]) Contains(key K) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.l1.Contains(key) || t.l2.contains(key)
+}
+
+// Delete removes given key from the cache.
+// Delete returns boolean indicating existence of a given key in the cache.
+// Error of deleting entry from disk is reported by DiskErr.
+func (t *TieredCache[K, V]) Delete(key K) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	inMemory := t.l1.Delete(key)
+	onDisk, err := t.l2.delete(key)
+	if err != nil {
+		t.diskFailed(err)
+	}
+
+	return inMemory || onDisk
+}
+
+// Len returns number of entries in memory and on disk.
+// Expired entries which are not removed yet are counted too.
+func (t *TieredCache[K, V]) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.l1.Len() + t.l2.len()
+}
+
+// Cap returns number of entries kept in memory.
+func (t *TieredCache[K, V]) Cap() int {
+	return t.l1.Cap()
+}
+
+// Keys returns keys of not expired entries from the most to the least recently used.
+// Keys of entries in memory go first, keys of entries on disk follow in order they were spilled.
+func (t *TieredCache[K, V]) Keys() []K {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append(t.l1.Keys(), t.l2.keys()...)
+}
+
+// Resize changes number of entries kept in memory. Entries which do not fit
+// into new capacity are spilled to disk. Resize returns number of spilled entries.
+// Errors of writing spilled entries are reported by DiskErr.
+func (t *TieredCache[K, V]) Resize(capacity int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.l1.Resize(capacity)
+}
+
+// Clear removes all data in memory and on disk.
+func (t *TieredCache[K, V]) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.l1.Clear()
+	// cleared index hides entries even if file was not truncated
+	_ = t.l2.clear()
+}
+
+// DiskErr returns the first error of the disk level which was not reported yet and forgets it.
+// Such errors are failures of writing an entry evicted from memory, which is lost then,
+// and failures of reading or deleting an entry on disk, e.g. ErrCorruptedStore.
+// Set reports them too, DiskErr allows to check them after other operations.
+func (t *TieredCache[K, V]) DiskErr() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.takeDiskErr()
+}
+
+// Stats returns statistics of the memory level.
+func (t *TieredCache[K, V]) Stats() Stats {
+	return t.l1.Stats()
+}
+
+// Compact rewrites the file dropping stale records. Compaction runs in background
+// automatically, Compact allows to run it at convenient moment.
+func (t *TieredCache[K, V]) Compact() error {
+	return t.l2.compactNow()
+}
+
+// Close writes entries kept in memory to disk, so they are available
+// to the next cache opened with the same file, and closes the file.
+// The cache must not be used after Close.
+func (t *TieredCache[K, V]) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.l1.Stop()
+
+	if err := t.flush(); err != nil {
+		t.l2.close()
+		return err
+	}
+
+	return t.l2.close()
+}
+
+// flush writes entries kept in memory to disk from the least to the most recently used,
+// so order of keys is preserved. It must be called with mutex held.
+func (t *TieredCache[K, V]) flush() error {
+	t.l1.mu.Lock()
+	defer t.l1.mu.Unlock()
+
+	now := t.clock.Now()
+	for i := t.l1.queue.Back(); i != 0; i = t.l1.queue.Prev(i) {
+		item := t.l1.queue.Value(i)
+		if item.expired(now) {
+			continue
+		}
+		if err := t.l2.put(item.key, item.value, item.expiresAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// promote stores entry read from disk in memory keeping its expiration time.
+// It returns false if entry does not fit into memory.
+func (t *TieredCache[K, V]) promote(key K, value V, expiresAt time.Time) bool {
+	t.l1.mu.Lock()
+	defer t.l1.unlock()
+
+	// entry would be spilled back at once
+	if t.l1.capacity == 0 {
+		return false
+	}
+
+	_, err := t.l1.setUntil(key, value, expiresAt, t.l1.costOf(value))
+
+	return err == nil
+}
+
+// spill writes entry evicted from memory for lack of capacity to disk.
+func (t *TieredCache[K, V]) spill(e eviction[K, V]) {
+	if e.reason != EvictCapacity {
+		return
+	}
+
+	if !e.expiresAt.IsZero() && !t.clock.Now().Before(e.expiresAt) {
+		return
+	}
+
+	if err := t.l2.put(e.key, e.value, e.expiresAt); err != nil {
+		t.diskFailed(err)
+	}
+}
+
+// diskFailed remembers error of the disk level unless an earlier one is not reported yet.
+// It must be called with mutex held.
+func (t *TieredCache[K, V]) diskFailed(err error) {
+	if t.diskErr == nil {
+		t.diskErr = err
+	}
+}
+
+// takeDiskErr returns error of the disk level which was not reported yet and forgets it.
+// It must be called with mutex held.
+func (t *TieredCache[K, V]) takeDiskErr() error {
+	err := t.diskErr
+	t.diskErr = nil
+
+	return err
+}
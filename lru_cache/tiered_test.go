@@ -0,0 +1,444 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTiered(t *testing.T, capacity int, path string, opts ...Option) *TieredCache[Key, interface{}] {
+	t.Helper()
+
+	c, err := NewTiered[Key, interface{}](capacity, path, opts...)
+	require.NoError(t, err)
+
+	return c
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	return info.Size()
+}
+
+func TestTieredCacheSpillsAndPromotes(t *testing.T) {
+	c := newTestTiered(t, 2, filepath.Join(t.TempDir(), "cache"))
+	defer c.Close()
+
+	mustSet(t, c, "testKey1", "testValue1")
+	mustSet(t, c, "testKey2", "testValue2")
+	mustSet(t, c, "testKey3", "testValue3")
+
+	assert.Equal(t, c.Len(), 3)
+	assert.Equal(t, c.l1.Len(), 2)
+	assert.True(t, c.l2.contains("testKey1"))
+	assert.Equal(t, c.Keys(), []Key{"testKey3", "testKey2", "testKey1"})
+
+	value, ok := c.Get("testKey1")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, "testValue1")
+	// promoted entry pushes the least recently used one to disk
+	assert.True(t, c.l1.Contains("testKey1"))
+	assert.True(t, c.l2.contains("testKey2"))
+	assert.False(t, c.l2.contains("testKey1"))
+	assert.Equal(t, c.Keys(), []Key{"testKey1", "testKey3", "testKey2"})
+	assert.Equal(t, c.Len(), 3)
+}
+
+func TestTieredCacheSetReplacesEntryOnDisk(t *testing.T) {
+	c := newTestTiered(t, 1, filepath.Join(t.TempDir(), "cache"))
+	defer c.Close()
+
+	mustSet(t, c, "testKey1", "testValue1")
+	mustSet(t, c, "testKey2", "testValue2")
+
+	assert.True(t, mustSet(t, c, "testKey1", "newTestValue1"))
+	assert.Equal(t, c.Len(), 2)
+
+	value, ok := c.Get("testKey1")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, "newTestValue1")
+}
+
+func TestTieredCachePeekDoesNotPromote(t *testing.T) {
+	c := newTestTiered(t, 1, filepath.Join(t.TempDir(), "cache"))
+	defer c.Close()
+
+	mustSet(t, c, "testKey1", "testValue1")
+	mustSet(t, c, "testKey2", "testValue2")
+
+	value, ok := c.Peek("testKey1")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, "testValue1")
+	assert.True(t, c.Contains("testKey1"))
+	assert.False(t, c.l1.Contains("testKey1"))
+
+	_, ok = c.Peek("nonexistentKey")
+
+	assert.False(t, ok)
+}
+
+func TestTieredCacheDelete(t *testing.T) {
+	c := newTestTiered(t, 1, filepath.Join(t.TempDir(), "cache"))
+	defer c.Close()
+
+	mustSet(t, c, "testKey1", "testValue1")
+	mustSet(t, c, "testKey2", "testValue2")
+
+	assert.True(t, c.Delete("testKey1"))
+	assert.True(t, c.Delete("testKey2"))
+	assert.False(t, c.Delete("testKey1"))
+	assert.Equal(t, c.Len(), 0)
+	assertKeyDoesNotExist(t, c, "testKey1")
+}
+
+func TestTieredCacheClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	c := newTestTiered(t, 1, path)
+	defer c.Close()
+
+	mustSet(t, c, "testKey1", "testValue1")
+	mustSet(t, c, "testKey2", "testValue2")
+
+	c.Clear()
+
+	assert.Equal(t, c.Len(), 0)
+	assert.Equal(t, fileSize(t, path), int64(0))
+	assertKeyDoesNotExist(t, c, "testKey1")
+	assertKeyDoesNotExist(t, c, "testKey2")
+}
+
+func TestTieredCacheResizeSpillsToDisk(t *testing.T) {
+	c := newTestTiered(t, 3, filepath.Join(t.TempDir(), "cache"))
+	defer c.Close()
+
+	mustSet(t, c, "testKey1", "testValue1")
+	mustSet(t, c, "testKey2", "testValue2")
+	mustSet(t, c, "testKey3", "testValue3")
+
+	assert.Equal(t, c.Resize(1), 2)
+	assert.Equal(t, c.Cap(), 1)
+	assert.Equal(t, c.Len(), 3)
+	assert.Equal(t, c.Keys(), []Key{"testKey3", "testKey2", "testKey1"})
+}
+
+// failDiskWrites replaces file of the cache with read only one, so writes fail while reads succeed.
+func failDiskWrites(t *testing.T, c *TieredCache[Key, interface{}], path string) {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	c.l2.file.Close()
+	c.l2.file = file
+}
+
+func TestTieredCacheReportsSpillErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	c := newTestTiered(t, 2, path)
+	defer c.Close()
+
+	mustSet(t, c, "testKey1", "testValue1")
+	mustSet(t, c, "testKey2", "testValue2")
+	mustSet(t, c, "testKey3", "testValue3")
+	failDiskWrites(t, c, path)
+
+	// promotion spills testKey2, which can not be written
+	value, ok := c.Get("testKey1")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, "testValue1")
+	assert.False(t, c.Contains("testKey2"))
+	assert.Error(t, c.DiskErr())
+	assert.NoError(t, c.DiskErr())
+
+	assert.Equal(t, c.Resize(1), 1)
+
+	// error of Resize is kept until reported, so the next Set returns it
+	_, err := c.Set("testKey4", "testValue4")
+
+	assert.Error(t, err)
+	assert.NoError(t, c.DiskErr())
+}
+
+func TestTieredCacheZeroCapacityKeepsEntriesOnDisk(t *testing.T) {
+	c := newTestTiered(t, 0, filepath.Join(t.TempDir(), "cache"))
+	defer c.Close()
+
+	assert.False(t, mustSet(t, c, "testKey1", "testValue1"))
+	assert.True(t, mustSet(t, c, "testKey1", "newTestValue1"))
+	mustSet(t, c, "testKey2", "testValue2")
+
+	value, ok := c.Get("testKey1")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, "newTestValue1")
+	assert.Equal(t, c.l1.Len(), 0)
+	assert.Equal(t, c.Len(), 2)
+	assert.Equal(t, c.Keys(), []Key{"testKey2", "testKey1"})
+}
+
+func TestTieredCacheReportsReadErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	c := newTestTiered(t, 1, path)
+	defer c.Close()
+
+	mustSet(t, c, "testKey1", "testValue1")
+	mustSet(t, c, "testKey2", "testValue2")
+
+	// damage payload of the record spilled to disk
+	e := c.l2.index["testKey1"]
+	file, err := os.OpenFile(path, os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteAt(bytes.Repeat([]byte{0xff}, int(e.size)-recordHeaderSize), e.offset+recordHeaderSize)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	_, ok := c.Peek("testKey1")
+
+	assert.False(t, ok)
+	assert.ErrorIs(t, c.DiskErr(), ErrCorruptedStore)
+
+	_, ok = c.Get("testKey1")
+
+	assert.False(t, ok)
+	assert.ErrorIs(t, c.DiskErr(), ErrCorruptedStore)
+	assert.NoError(t, c.DiskErr())
+}
+
+func TestTieredCacheExpirationOnDisk(t *testing.T) {
+	clock := newFakeClock()
+	c := newTestTiered(t, 1, filepath.Join(t.TempDir(), "cache"), WithClock(clock), WithDefaultTTL(time.Minute))
+	defer c.Close()
+
+	mustSet(t, c, "testKey1", "testValue1")
+	clock.Advance(30 * time.Second)
+	mustSet(t, c, "testKey2", "testValue2")
+
+	value, ok := c.Get("testKey1")
+
+	// promoted entry keeps its expiration time
+	assert.True(t, ok)
+	assert.Equal(t, value, "testValue1")
+
+	clock.Advance(30 * time.Second)
+
+	assertKeyDoesNotExist(t, c, "testKey1")
+	_, ok = c.Peek("testKey2")
+	assert.True(t, ok)
+
+	clock.Advance(30 * time.Second)
+
+	assertKeyDoesNotExist(t, c, "testKey2")
+	assert.Empty(t, c.Keys())
+}
+
+func TestTieredCacheReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	c := newTestTiered(t, 2, path)
+
+	mustSet(t, c, "testKey1", "testValue1")
+	mustSet(t, c, "testKey2", "testValue2")
+	mustSet(t, c, "testKey3", "testValue3")
+	c.Delete("testKey1")
+	mustSet(t, c, "testKey4", 4)
+	require.NoError(t, c.Close())
+
+	c = newTestTiered(t, 2, path)
+	defer c.Close()
+
+	assert.Equal(t, c.Len(), 3)
+	assert.Equal(t, c.Keys(), []Key{"testKey4", "testKey3", "testKey2"})
+	assertKeyDoesNotExist(t, c, "testKey1")
+
+	value, ok := c.Get("testKey4")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, 4)
+}
+
+func TestTieredCacheTruncatesIncompleteRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	c := newTestTiered(t, 1, path)
+	mustSet(t, c, "testKey1", "testValue1")
+	require.NoError(t, c.Close())
+
+	size := fileSize(t, path)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 1})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	c = newTestTiered(t, 1, path)
+	defer c.Close()
+
+	assert.Equal(t, fileSize(t, path), size)
+
+	value, ok := c.Get("testKey1")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, "testValue1")
+}
+
+func TestTieredCacheCorruptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	require.NoError(t, os.WriteFile(path, []byte{0, 0, 0, 3, 'b', 'a', 'd'}, 0o644))
+
+	_, err := NewTiered[Key, interface{}](1, path)
+
+	assert.ErrorIs(t, err, ErrCorruptedStore)
+}
+
+func TestTieredCacheCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	c := newTestTiered(t, 1, path)
+	defer c.Close()
+
+	for i := 0; i < 10; i++ {
+		// every set moves the other key to disk
+		mustSet(t, c, "testKey1", i)
+		mustSet(t, c, "testKey2", i)
+	}
+
+	size := fileSize(t, path)
+	require.NoError(t, c.Compact())
+
+	assert.Less(t, fileSize(t, path), size)
+	assert.Equal(t, c.Len(), 2)
+
+	value, ok := c.Get("testKey1")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, 9)
+
+	mustSet(t, c, "testKey3", 10)
+	assert.Equal(t, c.Keys(), []Key{"testKey3", "testKey1", "testKey2"})
+}
+
+func TestTieredCacheCompactsInBackground(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	c := newTestTiered(t, 1, path, WithCompactionThreshold(1))
+	defer c.Close()
+
+	for i := 0; i < 100; i++ {
+		mustSet(t, c, "testKey1", i)
+		mustSet(t, c, "testKey2", i)
+	}
+
+	assert.Eventually(t, func() bool {
+		c.l2.mu.Lock()
+		defer c.l2.mu.Unlock()
+
+		return c.l2.size <= 2*c.l2.live
+	}, time.Second, 10*time.Millisecond)
+
+	value, ok := c.Get("testKey1")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, 99)
+}
+
+func TestTieredCacheCompactionKeepsConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	c := newTestTiered(t, 1, path)
+	defer c.Close()
+
+	for i := 0; i < 10; i++ {
+		mustSet(t, c, "testKey1", i)
+		mustSet(t, c, "testKey2", i)
+	}
+	mustSet(t, c, "testKey3", 0)
+
+	compaction, err := c.l2.startCompaction()
+	require.NoError(t, err)
+
+	// store is not locked while records are copied
+	require.NoError(t, c.l2.put("testKey1", 100, time.Time{}))
+	require.NoError(t, c.l2.put("testKey4", 4, time.Time{}))
+	_, err = c.l2.delete("testKey2")
+	require.NoError(t, err)
+
+	offsets, err := compaction.rewrite()
+	require.NoError(t, err)
+	require.NoError(t, c.l2.finishCompaction(compaction, offsets))
+
+	assert.Equal(t, fileSize(t, path), c.l2.size)
+	assert.Equal(t, c.l2.keys(), []Key{"testKey4", "testKey1"})
+
+	value, ok := c.Get("testKey1")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, 100)
+	assert.False(t, c.Contains("testKey2"))
+
+	// compacted file is read back the same way
+	require.NoError(t, c.Close())
+	c = newTestTiered(t, 1, path)
+
+	assert.ElementsMatch(t, c.Keys(), []Key{"testKey1", "testKey3", "testKey4"})
+
+	value, ok = c.Get("testKey4")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, 4)
+}
+
+func TestTieredCacheCompactionIsDroppedAfterClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	c := newTestTiered(t, 1, path)
+	defer c.Close()
+
+	mustSet(t, c, "testKey1", 1)
+	mustSet(t, c, "testKey2", 2)
+
+	compaction, err := c.l2.startCompaction()
+	require.NoError(t, err)
+
+	c.Clear()
+	mustSet(t, c, "testKey3", 3)
+	mustSet(t, c, "testKey4", 4)
+
+	offsets, err := compaction.rewrite()
+	require.NoError(t, err)
+	assert.ErrorIs(t, c.l2.finishCompaction(compaction, offsets), errCompactionObsolete)
+
+	assert.Equal(t, c.Keys(), []Key{"testKey4", "testKey3"})
+
+	value, ok := c.Get("testKey3")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, 3)
+}
+
+func TestTieredCacheJSONCodec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	c, err := NewTiered[string, string](1, path, WithCodec(JSONCodec{}))
+	require.NoError(t, err)
+
+	_, err = c.Set("testKey1", "testValue1")
+	require.NoError(t, err)
+	_, err = c.Set("testKey2", "testValue2")
+	require.NoError(t, err)
+	require.NoError(t, c.Close())
+
+	c, err = NewTiered[string, string](1, path, WithCodec(JSONCodec{}))
+	require.NoError(t, err)
+	defer c.Close()
+
+	value, ok := c.Get("testKey1")
+
+	assert.True(t, ok)
+	assert.Equal(t, value, "testValue1")
+}
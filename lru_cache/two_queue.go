@@ -0,0 +1,256 @@
+package cache
+
+import "sync"
+
+var _ Cache[Key, interface{}] = (*TwoQueueCache[Key, interface{}])(nil)
+
+const (
+	// twoQueueRecentRatio is share of capacity for entries seen only once.
+	twoQueueRecentRatio = 0.25
+	// twoQueueGhostRatio is share of capacity for keys recently evicted from entries seen once.
+	twoQueueGhostRatio = 0.5
+)
+
+// TwoQueueCache is an implementation of a cache with the 2Q policy.
+// New entries are put into a small recent queue and promoted to the frequent queue
+// only when used again, so a single scan over many keys does not flush frequently used ones.
+// Keys evicted from the recent queue are remembered in a ghost queue without values,
+// so entries which come back soon after eviction are treated as frequent.
+// It implements Cache interface and is safe for concurrent use.
+// Capacity Unlimited means number of entries is not limited, cache of zero capacity stores nothing.
+type TwoQueueCache[K comparable, V any] struct {
+	mu         sync.Mutex
+	capacity   int
+	recentSize int
+	ghostSize  int
+	recent     *lruList[K, V]
+	frequent   *lruList[K, V]
+	ghost      *lruList[K, struct{}]
+	counters   counters
+}
+
+// New2Q returns pointer to newly created TwoQueueCache with given capacity.
+func New2Q[K comparable, V any](capacity int) *TwoQueueCache[K, V] {
+	c := &TwoQueueCache[K, V]{
+		recent:   newLRUList[K, V](),
+		frequent: newLRUList[K, V](),
+		ghost:    newLRUList[K, struct{}](),
+	}
+	c.setCapacity(capacity)
+
+	return c
+}
+
+// Set stores key with given value.
+// Sets return boolean indicating existence of a given key in the cache.
+func (c *TwoQueueCache[K, V]) Set(key K, value V) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counters.sets.Add(1)
+
+	// cache of zero capacity keeps nothing
+	if c.capacity == 0 {
+		c.counters.evictions.Add(1)
+		return false, nil
+	}
+
+	if e, ok := c.frequent.get(key); ok {
+		e.value = value
+		return true, nil
+	}
+
+	// second use promotes entry to frequent queue
+	if _, ok := c.recent.remove(key); ok {
+		c.frequent.add(key, value)
+		return true, nil
+	}
+
+	if _, ok := c.ghost.peek(key); ok {
+		c.ensureSpace(true)
+		c.ghost.remove(key)
+		c.frequent.add(key, value)
+
+		return false, nil
+	}
+
+	c.ensureSpace(false)
+	c.recent.add(key, value)
+
+	return false, nil
+}
+
+// Get returns value associated with a given key in the cache and
+// boolean indicating existence of key in the cache.
+func (c *TwoQueueCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.frequent.get(key); ok {
+		c.counters.hits.Add(1)
+		return e.value, true
+	}
+
+	if e, ok := c.recent.remove(key); ok {
+		c.counters.hits.Add(1)
+		c.frequent.add(key, e.value)
+
+		return e.value, true
+	}
+
+	c.counters.misses.Add(1)
+	var zero V
+
+	return zero, false
+}
+
+// Peek returns value associated with a given key in the cache and
+// boolean indicating existence of key in the cache without counting it as use.
+func (c *TwoQueueCache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.peek(key)
+}
+
+// Contains reports whether given key exists in the cache without counting it as use.
+func (c *TwoQueueCache[K, V]) Contains(key K) bool {
+	_, ok := c.Peek(key)
+
+	return ok
+}
+
+// Delete removes given key from the cache.
+// Delete returns boolean indicating existence of a given key in the cache.
+func (c *TwoQueueCache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ghost.remove(key)
+
+	if _, ok := c.frequent.remove(key); ok {
+		return true
+	}
+
+	_, ok := c.recent.remove(key)
+
+	return ok
+}
+
+// Len returns number of entries in the cache.
+func (c *TwoQueueCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.recent.len() + c.frequent.len()
+}
+
+// Cap returns capacity of the cache.
+func (c *TwoQueueCache[K, V]) Cap() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.capacity
+}
+
+// Keys returns keys of frequently used entries followed by keys of entries seen once.
+// Keys of each group are ordered from the most to the least recently used.
+func (c *TwoQueueCache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append(c.frequent.keys(), c.recent.keys()...)
+}
+
+// Resize changes capacity of the cache, evicting entries which do not fit into new capacity.
+// Resize returns number of evicted entries.
+func (c *TwoQueueCache[K, V]) Resize(capacity int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setCapacity(capacity)
+
+	evicted := 0
+	for c.recent.len()+c.frequent.len() > c.capacity {
+		c.evict(false)
+		evicted++
+	}
+
+	for c.ghost.len() > c.ghostSize {
+		c.ghost.removeOldest()
+	}
+
+	return evicted
+}
+
+// Clear removes all data in the cache.
+func (c *TwoQueueCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.recent.clear()
+	c.frequent.clear()
+	c.ghost.clear()
+}
+
+// Stats returns snapshot of the cache state.
+func (c *TwoQueueCache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{Len: c.recent.len() + c.frequent.len()}
+	c.counters.fill(&stats)
+
+	return stats
+}
+
+func (c *TwoQueueCache[K, V]) setCapacity(capacity int) {
+	c.capacity = max(capacity, 0)
+	c.recentSize = int(float64(c.capacity) * twoQueueRecentRatio)
+	c.ghostSize = int(float64(c.capacity) * twoQueueGhostRatio)
+}
+
+func (c *TwoQueueCache[K, V]) peek(key K) (V, bool) {
+	if e, ok := c.frequent.peek(key); ok {
+		return e.value, true
+	}
+
+	if e, ok := c.recent.peek(key); ok {
+		return e.value, true
+	}
+
+	var zero V
+
+	return zero, false
+}
+
+// ensureSpace evicts an entry if the cache is full.
+// ghostHit tells that new entry comes from the ghost queue.
+func (c *TwoQueueCache[K, V]) ensureSpace(ghostHit bool) {
+	if n := c.recent.len() + c.frequent.len(); n < c.capacity {
+		return
+	}
+
+	c.evict(ghostHit)
+}
+
+// evict removes single entry, preferring entries seen once while their queue exceeds its share.
+func (c *TwoQueueCache[K, V]) evict(ghostHit bool) {
+	c.counters.evictions.Add(1)
+
+	recentLen := c.recent.len()
+	if recentLen > 0 && (recentLen > c.recentSize || (recentLen == c.recentSize && !ghostHit)) {
+		e, _ := c.recent.removeOldest()
+		c.ghost.add(e.key, struct{}{})
+
+		if c.ghost.len() > c.ghostSize {
+			c.ghost.removeOldest()
+		}
+
+		return
+	}
+
+	if _, ok := c.frequent.removeOldest(); !ok {
+		c.recent.removeOldest()
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoQueueCachePromotesOnSecondUse(t *testing.T) {
+	c := New2Q[Key, string](4)
+	c.Set("testKey1", "testValue1")
+	c.Set("testKey2", "testValue2")
+
+	assert.True(t, c.recent.contains("testKey1"))
+
+	c.Get("testKey1")
+
+	assert.True(t, c.frequent.contains("testKey1"))
+	assert.False(t, c.recent.contains("testKey1"))
+	assert.Equal(t, c.Keys(), []Key{"testKey1", "testKey2"})
+}
+
+func TestTwoQueueCacheIsScanResistant(t *testing.T) {
+	c := New2Q[Key, int](8)
+
+	for _, key := range []Key{"hot1", "hot2", "hot3"} {
+		c.Set(key, 0)
+		c.Get(key)
+	}
+
+	for i := 0; i < 100; i++ {
+		c.Set(Key("scan"+strconv.Itoa(i)), i)
+	}
+
+	for _, key := range []Key{"hot1", "hot2", "hot3"} {
+		assert.True(t, c.Contains(key))
+	}
+}
+
+func TestTwoQueueCacheGhostHitGoesToFrequent(t *testing.T) {
+	c := New2Q[Key, int](4)
+	c.Set("testKey1", 1)
+
+	for i := 0; i < 4; i++ {
+		c.Set(Key(strconv.Itoa(i)), i)
+	}
+
+	assert.False(t, c.Contains("testKey1"))
+	assert.True(t, c.ghost.contains("testKey1"))
+
+	c.Set("testKey1", 1)
+
+	assert.True(t, c.frequent.contains("testKey1"))
+	assert.False(t, c.ghost.contains("testKey1"))
+}
+
+func TestTwoQueueCacheStats(t *testing.T) {
+	c := New2Q[Key, string](4)
+	c.Set("testKey1", "testValue1")
+	c.Get("testKey1")
+	c.Get("nonexistentKey")
+
+	stats := c.Stats()
+
+	assert.Equal(t, stats.Len, 1)
+	assert.Equal(t, stats.Hits, uint64(1))
+	assert.Equal(t, stats.Misses, uint64(1))
+}
@@ -0,0 +1,108 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock provides time to Limiter, so it can be replaced in tests.
+type Clock interface {
+	Now() time.Time
+	// Sleep blocks for given duration or until ctx is done, in which case it returns ctx.Err().
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Limiter is a token bucket which is refilled with rate tokens per second and holds up to burst tokens.
+// It is safe for concurrent use, so one Limiter may be shared by several runs.
+type Limiter struct {
+	// interval is time needed to refill one token
+	interval time.Duration
+	burst    int
+	clock    Clock
+
+	mu sync.Mutex
+	// tat is theoretical arrival time of the next token, when the bucket is empty
+	tat time.Time
+}
+
+// NewLimiter returns pointer to newly created Limiter with full bucket.
+// Non-positive rate means no limit, burst less than one means one. Nil clock means system clock.
+func NewLimiter(rate float64, burst int, clock Clock) *Limiter {
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	l := &Limiter{
+		burst: max(burst, 1),
+		clock: clock,
+	}
+	if rate > 0 {
+		l.interval = time.Duration(float64(time.Second) / rate)
+	}
+
+	return l
+}
+
+// Wait blocks until a token is available and takes it.
+// It returns ctx.Err() without taking a token if ctx is done before that.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		delay := l.reserve()
+		if delay <= 0 {
+			return nil
+		}
+
+		if err := l.clock.Sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token if it is available, otherwise it returns time until it is.
+func (l *Limiter) reserve() time.Duration {
+	if l.interval == 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	tat := l.tat
+	if tat.Before(now) {
+		tat = now
+	}
+
+	// the bucket is full when tat is now, every taken token moves it forward by interval
+	allowAt := tat.Add(-time.Duration(l.burst-1) * l.interval)
+	if now.Before(allowAt) {
+		return allowAt.Sub(now)
+	}
+
+	l.tat = tat.Add(l.interval)
+
+	return 0
+}
@@ -0,0 +1,235 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a Clock which moves only when Advance is called.
+type fakeClock struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	now      time.Time
+	sleepers []*sleeper
+}
+
+type sleeper struct {
+	until time.Time
+	done  chan struct{}
+}
+
+func newFakeClock() *fakeClock {
+	c := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c.cond = sync.NewCond(&c.mu)
+
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	s := &sleeper{until: c.now.Add(d), done: make(chan struct{})}
+	c.sleepers = append(c.sleepers, s)
+	c.cond.Broadcast()
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		for i := range c.sleepers {
+			if c.sleepers[i] == s {
+				c.sleepers = append(c.sleepers[:i:i], c.sleepers[i+1:]...)
+				break
+			}
+		}
+
+		return ctx.Err()
+	case <-s.done:
+		return nil
+	}
+}
+
+// Advance moves the clock forward and wakes up sleepers whose time has come.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	sleeping := c.sleepers[:0]
+	for _, s := range c.sleepers {
+		if c.now.Before(s.until) {
+			sleeping = append(sleeping, s)
+		} else {
+			close(s.done)
+		}
+	}
+	c.sleepers = sleeping
+}
+
+// waitSleepers blocks until given number of goroutines sleep.
+func (c *fakeClock) waitSleepers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.sleepers) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *fakeClock) sleeping() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.sleepers)
+}
+
+func TestLimiterBurstThenRate(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(10, 3, clock)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, l.Wait(context.Background()))
+	}
+	assert.Equal(t, clock.sleeping(), 0)
+
+	done := make(chan error)
+	go func() {
+		done <- l.Wait(context.Background())
+	}()
+
+	clock.waitSleepers(1)
+	clock.Advance(50 * time.Millisecond)
+
+	assert.Equal(t, clock.sleeping(), 1)
+
+	clock.Advance(50 * time.Millisecond)
+
+	assert.NoError(t, <-done)
+	assert.Equal(t, l.reserve(), 100*time.Millisecond)
+}
+
+func TestLimiterRefillsUpToBurst(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(10, 2, clock)
+
+	clock.Advance(time.Minute)
+
+	assert.Zero(t, l.reserve())
+	assert.Zero(t, l.reserve())
+	assert.Equal(t, l.reserve(), 100*time.Millisecond)
+
+	// one and a half tokens are refilled
+	clock.Advance(150 * time.Millisecond)
+
+	assert.Zero(t, l.reserve())
+	assert.Equal(t, l.reserve(), 50*time.Millisecond)
+
+	clock.Advance(time.Minute)
+
+	assert.Zero(t, l.reserve())
+	assert.Zero(t, l.reserve())
+	assert.Equal(t, l.reserve(), 100*time.Millisecond)
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(10, 1, clock)
+	require.NoError(t, l.Wait(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- l.Wait(ctx)
+	}()
+
+	clock.waitSleepers(1)
+	cancel()
+
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, clock.sleeping(), 0)
+
+	// cancelled wait does not take a token
+	clock.Advance(100 * time.Millisecond)
+
+	assert.Zero(t, l.reserve())
+}
+
+func TestLimiterWithoutRate(t *testing.T) {
+	l := NewLimiter(0, 0, nil)
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, l.Wait(context.Background()))
+	}
+}
+
+func TestRunContextLimiterGatesDispatch(t *testing.T) {
+	clock := newFakeClock()
+	started := make(chan int)
+
+	tasks := make([]ContextTask, 5)
+	for i := range tasks {
+		i := i
+		tasks[i] = func(context.Context) error {
+			started <- i
+			return nil
+		}
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- RunContext(context.Background(), tasks, Options{Workers: 5, Limiter: NewLimiter(10, 2, clock)})
+	}()
+
+	// burst is started at once
+	assert.ElementsMatch(t, []int{<-started, <-started}, []int{0, 1})
+
+	for i := 2; i < len(tasks); i++ {
+		// the feeder waits for a token, so no task can start until the clock moves
+		clock.waitSleepers(1)
+		clock.Advance(100 * time.Millisecond)
+
+		assert.Equal(t, <-started, i)
+	}
+
+	assert.NoError(t, <-done)
+}
+
+func TestRunReportLimiterCancelled(t *testing.T) {
+	clock := newFakeClock()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	tasks := []ContextTask{
+		func(context.Context) error {
+			close(started)
+			return nil
+		},
+		func(context.Context) error { return nil },
+		func(context.Context) error { return nil },
+	}
+
+	go func() {
+		<-started
+		clock.waitSleepers(1)
+		cancel()
+	}()
+
+	report, err := RunReport(ctx, tasks, Options{Workers: 3, Limiter: NewLimiter(1, 1, clock)})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, report.Skipped(), 2)
+}
@@ -1,64 +1,439 @@
 package parallel
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
 	"sync"
+	"time"
 )
 
 var ErrErrorsLimitExceeded = errors.New("errors limit exceeded")
 
 type Task func() error
 
-// worker gets task from tasks channel, runs it and puts result into results channel
-// When it receives message from quit channel, it stops.
+// ContextTask is a task which receives context of the run.
+// The context is cancelled when the run is abandoned, so the task should stop as soon as possible.
+type ContextTask func(ctx context.Context) error
+
+// Options configure RunContext.
+type Options struct {
+	// Workers is number of tasks running concurrently. Non-positive value means one.
+	Workers int
+	// MaxErrors is number of failed tasks tolerated by the run.
+	// Non-positive value means the first error stops the run.
+	MaxErrors int
+	// Limiter gates dispatch of tasks, so they are started no faster than it allows.
+	// Nil means tasks are started as soon as a worker is free.
+	Limiter *Limiter
+	// Retry configures running failed tasks again. Nil means tasks are run once.
+	Retry *RetryPolicy
+	// MaxWeight caps total weight of running tasks. Tasks run with RunWeighted declare their weights,
+	// others weigh one. Non-positive value means only Workers limits running tasks.
+	MaxWeight int64
+}
+
+// Run runs tasks in n goroutines and stops when more than m tasks failed.
+// Non-positive m means the first error stops the run.
+// Run returns ErrErrorsLimitExceeded if tasks failed more than m times.
 func Run(tasks []Task, n int, m int) error {
-	tasksCh := make(chan func() error)
-	wg := sync.WaitGroup{}
-	mu := sync.Mutex{}
+	contextTasks := make([]ContextTask, len(tasks))
+	for i, task := range tasks {
+		contextTasks[i] = withContext(task)
+	}
+
+	return RunContext(context.Background(), contextTasks, Options{Workers: n, MaxErrors: m})
+}
+
+// RunIter runs tasks returned by next like Run, starting them as soon as they are returned.
+// Next is not called after more than m tasks failed, but RunIter waits for the call in progress to return.
+func RunIter(next func() (Task, bool), n int, m int) error {
+	source := func(context.Context) (ContextTask, bool) {
+		task, ok := next()
+		if !ok {
+			return nil, false
+		}
 
-	if m < 0 {
-		m = 0
+		return withContext(task), true
 	}
 
-	go func() {
-		defer close(tasksCh)
-		for _, task := range tasks {
-			mu.Lock()
-			if m < 0 {
-				mu.Unlock()
-				break
+	return RunSource(context.Background(), source, Options{Workers: n, MaxErrors: m})
+}
+
+// RunChan runs tasks received from the channel like Run, until it is closed.
+// Once more than m tasks failed, RunChan stops waiting for tasks and returns even if the channel
+// is not closed. Tasks are not received after that, so the producer must not block on sending forever.
+func RunChan(tasks <-chan Task, n int, m int) error {
+	source := func(ctx context.Context) (ContextTask, bool) {
+		select {
+		case task, ok := <-tasks:
+			if !ok {
+				return nil, false
 			}
-			mu.Unlock()
-			tasksCh <- task
+
+			return withContext(task), true
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+
+	return RunSource(context.Background(), source, Options{Workers: n, MaxErrors: m})
+}
+
+func withContext(task Task) ContextTask {
+	return func(context.Context) error {
+		return task()
+	}
+}
+
+// Source returns the next task of the run, or false if there are no more tasks.
+// It is called by a single goroutine and receives context of the run, so it may stop waiting
+// for the next task once the run is stopped. It is not called after that.
+type Source func(ctx context.Context) (ContextTask, bool)
+
+// ChanSource returns Source receiving tasks from the channel until it is closed.
+func ChanSource(tasks <-chan ContextTask) Source {
+	return func(ctx context.Context) (ContextTask, bool) {
+		select {
+		case task, ok := <-tasks:
+			return task, ok
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+// RunSource runs tasks returned by source like RunContext, starting them as soon as they are returned,
+// so tasks do not need to be created up front.
+func RunSource(ctx context.Context, source Source, opts Options) error {
+	r := newRunner(ctx, opts)
+	defer r.cancel()
+
+	r.run(source.jobs(), r.opts.Workers)
+
+	return r.err()
+}
+
+// sliceSource returns Source returning given tasks in order.
+func sliceSource(tasks []ContextTask) Source {
+	i := 0
+
+	return func(context.Context) (ContextTask, bool) {
+		if i == len(tasks) {
+			return nil, false
+		}
+		i++
+
+		return tasks[i-1], true
+	}
+}
+
+// jobs returns jobSource numbering tasks of the source in order they are returned, every one weighing one.
+func (s Source) jobs() jobSource {
+	i := -1
+
+	return func(ctx context.Context) (job, bool) {
+		task, ok := s(ctx)
+		if !ok {
+			return job{}, false
+		}
+		i++
+
+		return job{index: i, task: task, weight: 1}, true
+	}
+}
+
+// RunContext runs tasks concurrently as configured by opts. Tasks receive context which is
+// cancelled when ctx is cancelled or when more than opts.MaxErrors tasks failed.
+// After that no new tasks are started and RunContext returns once running tasks are finished,
+// with ErrErrorsLimitExceeded or ctx.Err() respectively.
+// Panics of tasks are recovered and counted as errors of type *PanicError.
+func RunContext(ctx context.Context, tasks []ContextTask, opts Options) error {
+	r := newRunner(ctx, opts)
+	defer r.cancel()
+
+	r.run(sliceSource(tasks).jobs(), len(tasks))
+
+	return r.err()
+}
+
+// TaskResult describes how a task of the run finished.
+type TaskResult struct {
+	// Index is index of the task in the run.
+	Index int
+	// Err is error returned by the last attempt of the task.
+	Err error
+	// Duration is time the task was running, including delays between attempts.
+	Duration time.Duration
+	// Attempts is number of times the task was run, zero if it was skipped.
+	Attempts int
+	// Skipped reports that the task was not started because the run was stopped.
+	Skipped bool
+}
+
+// Report describes results of all tasks of the run.
+type Report struct {
+	// Results are ordered by index of the task.
+	Results []TaskResult
+}
+
+// Failed returns results of tasks which returned an error.
+func (r Report) Failed() []TaskResult {
+	return r.filter(func(result TaskResult) bool { return result.Err != nil })
+}
+
+// Skipped returns results of tasks which were not started.
+func (r Report) Skipped() []TaskResult {
+	return r.filter(func(result TaskResult) bool { return result.Skipped })
+}
+
+func (r Report) filter(fn func(result TaskResult) bool) []TaskResult {
+	results := make([]TaskResult, 0)
+	for _, result := range r.Results {
+		if fn(result) {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// TaskError is an error of the task with given index.
+type TaskError struct {
+	Index int
+	Err   error
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %d: %v", e.Index, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is an error of the task which panicked.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is stack trace of the goroutine at the moment of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// call runs the task, converting its panic into *PanicError, so it does not crash the process.
+func call(ctx context.Context, task ContextTask) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
 		}
 	}()
 
-	wg.Add(n)
-	for i := 0; i < n; i++ {
-		go func() {
-			defer wg.Done()
-			for task := range tasksCh {
-				result := task()
+	return task(ctx)
+}
 
-				mu.Lock()
-				if result != nil {
-					m--
-				}
+// RunError aggregates errors of failed tasks together with the reason the run was stopped.
+// Like errors returned by errors.Join it unwraps to all of them, so errors.Is and errors.As
+// find ErrErrorsLimitExceeded, context errors and errors of the tasks.
+type RunError struct {
+	// Cause is ErrErrorsLimitExceeded or error of the context if the run was stopped, nil otherwise.
+	Cause error
+	// Errors are *TaskError values ordered by index of the task.
+	Errors []error
+}
+
+func (e *RunError) Error() string {
+	errs := e.Unwrap()
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (e *RunError) Unwrap() []error {
+	if e.Cause == nil {
+		return e.Errors
+	}
+
+	return append([]error{e.Cause}, e.Errors...)
+}
+
+// RunReport runs tasks like RunContext and reports result of every task.
+// It returns *RunError if any task failed or the run was stopped, even if errors limit was not exceeded.
+func RunReport(ctx context.Context, tasks []ContextTask, opts Options) (Report, error) {
+	r := newRunner(ctx, opts)
+	defer r.cancel()
+
+	r.results = make([]TaskResult, len(tasks))
+	for i := range r.results {
+		r.results[i] = TaskResult{Index: i, Skipped: true}
+	}
+
+	r.run(sliceSource(tasks).jobs(), len(tasks))
 
-				if m < 0 {
-					mu.Unlock()
+	report := Report{Results: r.results}
+	runErr := &RunError{Cause: r.err()}
+	for _, result := range report.Failed() {
+		runErr.Errors = append(runErr.Errors, &TaskError{Index: result.Index, Err: result.Err})
+	}
+
+	if runErr.Cause == nil && len(runErr.Errors) == 0 {
+		return report, nil
+	}
+
+	return report, runErr
+}
+
+// runner keeps state of a single run.
+type runner struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	opts   Options
+
+	// results are kept only when reported, each one is written by worker running the task
+	results []TaskResult
+	// sem holds weights of running tasks, it is nil if weight is not limited
+	sem *semaphore
+
+	mu       sync.Mutex
+	errors   int
+	exceeded bool
+}
+
+func newRunner(ctx context.Context, opts Options) *runner {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.MaxErrors < 0 {
+		opts.MaxErrors = 0
+	}
+	if opts.Retry != nil {
+		opts.Retry = opts.Retry.normalized()
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+
+	r := &runner{
+		parent: ctx,
+		ctx:    runCtx,
+		cancel: cancel,
+		opts:   opts,
+	}
+	if opts.MaxWeight > 0 {
+		r.sem = newSemaphore(opts.MaxWeight)
+	}
+
+	return r
+}
+
+// job is a task dispatched to a worker.
+type job struct {
+	index  int
+	task   ContextTask
+	weight int64
+}
+
+// jobSource returns the next job of the run, or false if there are no more jobs.
+type jobSource func(ctx context.Context) (job, bool)
+
+// run feeds jobs of the source to workers until it is exhausted or the run is cancelled,
+// and waits for workers to finish. Size limits number of workers if number of tasks is known.
+func (r *runner) run(source jobSource, size int) {
+	jobs := make(chan job)
+
+	go func() {
+		defer close(jobs)
+		for r.ctx.Err() == nil {
+			j, ok := source(r.ctx)
+			if !ok {
+				return
+			}
+
+			if r.opts.Limiter != nil && r.opts.Limiter.Wait(r.ctx) != nil {
+				return
+			}
+
+			if r.sem != nil {
+				// task heavier than the cap would never run, so it runs alone
+				j.weight = min(j.weight, r.sem.size)
+				if r.sem.acquire(r.ctx, j.weight) != nil {
 					return
 				}
-				mu.Unlock()
+			}
+
+			select {
+			case jobs <- j:
+			case <-r.ctx.Done():
+				r.release(j)
+				return
+			}
+		}
+	}()
+
+	workers := min(r.opts.Workers, size)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				// task may be dispatched together with cancellation
+				if r.ctx.Err() == nil {
+					r.execute(j.index, j.task)
+				}
+				r.release(j)
 			}
 		}()
 	}
 
 	wg.Wait()
+}
+
+func (r *runner) release(j job) {
+	if r.sem != nil {
+		r.sem.release(j.weight)
+	}
+}
+
+// done records result of a task and cancels the run once errors limit is exceeded.
+func (r *runner) done(err error) {
+	if err == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errors++
+	// errors caused by cancellation of the parent are not counted against the limit
+	if r.errors > r.opts.MaxErrors && r.parent.Err() == nil && !r.exceeded {
+		r.exceeded = true
+		r.cancel()
+	}
+}
+
+func (r *runner) err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	if m < 0 {
+	if r.exceeded {
 		return ErrErrorsLimitExceeded
 	}
 
-	return nil
+	return r.parent.Err()
 }
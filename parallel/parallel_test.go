@@ -1,12 +1,16 @@
 package parallel
 
 import (
+	"context"
 	"errors"
 	"math/rand"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const MaxSleepTime = 3
@@ -115,3 +119,355 @@ func TestRunEnsureTasksRunningConcurrently(t *testing.T) {
 
 	assert.LessOrEqual(t, elapsed, totalTasksDuration)
 }
+
+func TestRunContextCompletesAllTasks(t *testing.T) {
+	var started atomic.Int64
+	tasks := make([]ContextTask, 50)
+	for i := range tasks {
+		tasks[i] = func(context.Context) error {
+			started.Add(1)
+			return nil
+		}
+	}
+
+	assert.NoError(t, RunContext(context.Background(), tasks, Options{Workers: 5}))
+	assert.Equal(t, started.Load(), int64(50))
+}
+
+func TestRunContextParentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	running := make(chan struct{}, 10)
+
+	var started atomic.Int64
+	tasks := make([]ContextTask, 10)
+	for i := range tasks {
+		tasks[i] = func(ctx context.Context) error {
+			started.Add(1)
+			running <- struct{}{}
+			<-ctx.Done()
+
+			return ctx.Err()
+		}
+	}
+
+	go func() {
+		<-running
+		<-running
+		cancel()
+	}()
+
+	// errors caused by cancellation do not exceed the limit
+	err := RunContext(ctx, tasks, Options{Workers: 2})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, started.Load(), int64(2))
+}
+
+func TestRunContextAlreadyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var started atomic.Int64
+	tasks := []ContextTask{func(context.Context) error {
+		started.Add(1)
+		return nil
+	}}
+
+	assert.ErrorIs(t, RunContext(ctx, tasks, Options{Workers: 1}), context.Canceled)
+	assert.Equal(t, started.Load(), int64(0))
+}
+
+func TestRunContextErrorsLimitCancelsTasks(t *testing.T) {
+	blocked := make(chan struct{})
+	var cancelled atomic.Int64
+
+	tasks := []ContextTask{
+		func(ctx context.Context) error {
+			close(blocked)
+			<-ctx.Done()
+			cancelled.Add(1)
+
+			return ctx.Err()
+		},
+		func(context.Context) error {
+			<-blocked
+			return errors.New("Error during calculation")
+		},
+		func(context.Context) error {
+			t.Error("task must not start after errors limit is exceeded")
+			return nil
+		},
+	}
+
+	err := RunContext(context.Background(), tasks, Options{Workers: 2, MaxErrors: 0})
+
+	assert.Equal(t, err, ErrErrorsLimitExceeded)
+	assert.Equal(t, cancelled.Load(), int64(1))
+}
+
+func TestRunContextToleratesErrorsUnderLimit(t *testing.T) {
+	tasks := make([]ContextTask, 10)
+	for i := range tasks {
+		i := i
+		tasks[i] = func(context.Context) error {
+			if i%2 == 0 {
+				return errors.New("Error during calculation")
+			}
+
+			return nil
+		}
+	}
+
+	assert.NoError(t, RunContext(context.Background(), tasks, Options{Workers: 3, MaxErrors: 5}))
+	assert.Equal(t, RunContext(context.Background(), tasks, Options{Workers: 3, MaxErrors: 4}), ErrErrorsLimitExceeded)
+}
+
+func TestRunReportCompletesAllTasks(t *testing.T) {
+	errTask := errors.New("Error during calculation")
+	tasks := []ContextTask{
+		func(context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		},
+		func(context.Context) error { return errTask },
+		func(context.Context) error { return nil },
+	}
+
+	report, err := RunReport(context.Background(), tasks, Options{Workers: 2, MaxErrors: 1})
+
+	var runErr *RunError
+	require.ErrorAs(t, err, &runErr)
+	assert.NoError(t, runErr.Cause)
+	assert.ErrorIs(t, err, errTask)
+	assert.NotErrorIs(t, err, ErrErrorsLimitExceeded)
+
+	var taskErr *TaskError
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, taskErr.Index, 1)
+	assert.Equal(t, err.Error(), "task 1: Error during calculation")
+
+	require.Len(t, report.Results, 3)
+	for i, result := range report.Results {
+		assert.Equal(t, result.Index, i)
+		assert.False(t, result.Skipped)
+	}
+	assert.GreaterOrEqual(t, report.Results[0].Duration, 10*time.Millisecond)
+	assert.Equal(t, report.Failed(), []TaskResult{report.Results[1]})
+	assert.Empty(t, report.Skipped())
+}
+
+func TestRunReportNoErrors(t *testing.T) {
+	tasks := []ContextTask{
+		func(context.Context) error { return nil },
+		func(context.Context) error { return nil },
+	}
+
+	report, err := RunReport(context.Background(), tasks, Options{Workers: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Results, 2)
+	assert.Empty(t, report.Failed())
+}
+
+func TestRunReportErrorsLimitExceeded(t *testing.T) {
+	errTask := errors.New("Error during calculation")
+	tasks := []ContextTask{
+		func(context.Context) error { return errTask },
+		func(context.Context) error { return errTask },
+		func(context.Context) error { return nil },
+		func(context.Context) error { return nil },
+	}
+
+	report, err := RunReport(context.Background(), tasks, Options{Workers: 1, MaxErrors: 1})
+
+	assert.ErrorIs(t, err, ErrErrorsLimitExceeded)
+	assert.ErrorIs(t, err, errTask)
+	assert.Equal(t, err.Error(), strings.Join([]string{
+		"errors limit exceeded",
+		"task 0: Error during calculation",
+		"task 1: Error during calculation",
+	}, "\n"))
+
+	assert.Len(t, report.Failed(), 2)
+	skipped := report.Skipped()
+	require.Len(t, skipped, 2)
+	assert.Equal(t, skipped[0].Index, 2)
+	assert.Equal(t, skipped[1].Index, 3)
+	assert.Zero(t, skipped[0].Duration)
+}
+
+func TestRunReportParentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := RunReport(ctx, []ContextTask{func(context.Context) error { return nil }}, Options{})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrErrorsLimitExceeded)
+	assert.Len(t, report.Skipped(), 1)
+}
+
+func TestRunRecoversPanics(t *testing.T) {
+	tasks := []Task{
+		func() error {
+			panic("something went wrong")
+		},
+		createTask(0),
+	}
+
+	assert.NoError(t, Run(tasks, 2, 1))
+	assert.Equal(t, Run(tasks, 2, 0), ErrErrorsLimitExceeded)
+}
+
+func TestRunReportPanicError(t *testing.T) {
+	errCause := errors.New("Error during calculation")
+	tasks := []ContextTask{
+		func(context.Context) error {
+			panic("something went wrong")
+		},
+		func(context.Context) error {
+			panic(errCause)
+		},
+	}
+
+	report, err := RunReport(context.Background(), tasks, Options{Workers: 2, MaxErrors: 2})
+
+	assert.NotErrorIs(t, err, ErrErrorsLimitExceeded)
+	assert.ErrorIs(t, err, errCause)
+	require.Len(t, report.Failed(), 2)
+
+	var panicErr *PanicError
+	require.ErrorAs(t, report.Results[0].Err, &panicErr)
+	assert.Equal(t, panicErr.Value, "something went wrong")
+	assert.Equal(t, panicErr.Error(), "task panicked: something went wrong")
+	assert.Contains(t, string(panicErr.Stack), "TestRunReportPanicError")
+	assert.NoError(t, panicErr.Unwrap())
+}
+
+func TestRunReportRetriesPanics(t *testing.T) {
+	panicked := false
+	task := func(context.Context) error {
+		if !panicked {
+			panicked = true
+			panic("something went wrong")
+		}
+
+		return nil
+	}
+
+	report, err := RunReport(context.Background(), []ContextTask{task}, Options{Retry: &RetryPolicy{MaxAttempts: 2}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, report.Results[0].Attempts, 2)
+}
+
+func TestRunIterStartsTasksImmediately(t *testing.T) {
+	started := make(chan struct{})
+	produced := 0
+
+	next := func() (Task, bool) {
+		produced++
+		switch produced {
+		case 1:
+			return func() error {
+				close(started)
+				return nil
+			}, true
+		case 2:
+			// the first task runs before the rest are produced
+			<-started
+			return createTask(0), true
+		default:
+			return nil, false
+		}
+	}
+
+	assert.NoError(t, RunIter(next, 2, 0))
+	assert.Equal(t, produced, 3)
+}
+
+func TestRunIterStopsProducerWhenErrorsLimitExceeded(t *testing.T) {
+	var produced atomic.Int64
+	next := func() (Task, bool) {
+		produced.Add(1)
+		return createTaskWithError(0), true
+	}
+
+	assert.Equal(t, RunIter(next, 1, 2), ErrErrorsLimitExceeded)
+	// three failed tasks and at most one produced while the last of them was running
+	assert.LessOrEqual(t, produced.Load(), int64(4))
+}
+
+func TestRunChan(t *testing.T) {
+	var completed atomic.Int64
+	tasks := make(chan Task)
+
+	go func() {
+		defer close(tasks)
+		for i := 0; i < 10; i++ {
+			tasks <- func() error {
+				completed.Add(1)
+				return nil
+			}
+		}
+	}()
+
+	assert.NoError(t, RunChan(tasks, 3, 0))
+	assert.Equal(t, completed.Load(), int64(10))
+}
+
+func TestRunChanErrorsLimitExceeded(t *testing.T) {
+	tasks := make(chan Task)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		for {
+			select {
+			case tasks <- createTaskWithError(0):
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	assert.Equal(t, RunChan(tasks, 3, 5), ErrErrorsLimitExceeded)
+}
+
+func TestRunChanReturnsWhenProducerStalls(t *testing.T) {
+	tasks := make(chan Task)
+
+	// the channel is never closed, producer stalls once it sent tasks exceeding the limit
+	go func() {
+		for i := 0; i < 6; i++ {
+			tasks <- createTaskWithError(0)
+		}
+	}()
+
+	done := make(chan error)
+	go func() {
+		done <- RunChan(tasks, 3, 5)
+	}()
+
+	select {
+	case err := <-done:
+		assert.Equal(t, err, ErrErrorsLimitExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunChan does not return after errors limit is exceeded")
+	}
+}
+
+func TestRunSourceCancelledWhileWaitingForTasks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tasks := make(chan ContextTask)
+
+	go func() {
+		tasks <- func(context.Context) error {
+			cancel()
+			return nil
+		}
+	}()
+
+	// nothing is sent after the first task, so only cancellation stops the run
+	assert.ErrorIs(t, RunSource(ctx, ChanSource(tasks), Options{Workers: 2}), context.Canceled)
+}
@@ -0,0 +1,91 @@
+package parallel
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures how failed tasks are run again. Delay before every next attempt is doubled,
+// starting with BaseDelay. Only tasks which failed on their last attempt count against the errors limit.
+type RetryPolicy struct {
+	// MaxAttempts is maximum number of times a task is run. Non-positive value means one.
+	MaxAttempts int
+	// BaseDelay is delay before the second attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps delay between attempts. Zero means no cap.
+	MaxDelay time.Duration
+	// Jitter is fraction of delay which is randomized, from 0 to 1, so tasks failed together
+	// are not retried together. Delay is chosen uniformly from [delay*(1-Jitter), delay].
+	Jitter float64
+	// Retryable reports whether a task failed with given error should be run again.
+	// Nil means all errors are retryable.
+	Retryable func(err error) bool
+	// Clock is used to wait between attempts. Nil means system clock.
+	Clock Clock
+}
+
+// normalized returns copy of the policy with defaults applied.
+func (p RetryPolicy) normalized() *RetryPolicy {
+	p.MaxAttempts = max(p.MaxAttempts, 1)
+	p.Jitter = min(max(p.Jitter, 0), 1)
+	if p.Clock == nil {
+		p.Clock = systemClock{}
+	}
+
+	return &p
+}
+
+// delay returns time to wait after given number of failed attempts.
+func (p *RetryPolicy) delay(attempts int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		// stop doubling once the cap is reached or the duration would overflow
+		if (p.MaxDelay > 0 && d >= p.MaxDelay) || d > d*2 {
+			break
+		}
+		d *= 2
+	}
+
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	return d - time.Duration(rand.Float64()*p.Jitter*float64(d)) //nolint:gosec // jitter needs no secure random
+}
+
+// retry reports whether a task failed with err after given number of attempts should be run again,
+// and waits for that.
+func (r *runner) retry(attempts int, err error) bool {
+	p := r.opts.Retry
+	if p == nil || attempts >= p.MaxAttempts || r.ctx.Err() != nil {
+		return false
+	}
+
+	if p.Retryable != nil && !p.Retryable(err) {
+		return false
+	}
+
+	if d := p.delay(attempts); d > 0 && p.Clock.Sleep(r.ctx, d) != nil {
+		return false
+	}
+
+	// retries are calls too, so they are limited like first attempts
+	return r.opts.Limiter == nil || r.opts.Limiter.Wait(r.ctx) == nil
+}
+
+// execute runs the task with given index, retrying it as configured, and records its result.
+func (r *runner) execute(i int, task ContextTask) {
+	start := time.Now()
+
+	attempts := 1
+	err := call(r.ctx, task)
+	for err != nil && r.retry(attempts, err) {
+		attempts++
+		err = call(r.ctx, task)
+	}
+
+	if r.results != nil {
+		r.results[i] = TaskResult{Index: i, Err: err, Duration: time.Since(start), Attempts: attempts}
+	}
+	r.done(err)
+}
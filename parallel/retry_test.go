@@ -0,0 +1,161 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingTask returns a task which fails given number of times and succeeds after that.
+func failingTask(failures int, err error) ContextTask {
+	return func(context.Context) error {
+		if failures > 0 {
+			failures--
+			return err
+		}
+
+		return nil
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempts, expected := range map[int]time.Duration{
+		1:    100 * time.Millisecond,
+		2:    200 * time.Millisecond,
+		3:    400 * time.Millisecond,
+		4:    800 * time.Millisecond,
+		5:    time.Second,
+		1000: time.Second,
+	} {
+		assert.Equal(t, p.delay(attempts), expected, attempts)
+	}
+
+	// without the cap delay stops growing before overflow
+	p.MaxDelay = 0
+	assert.Greater(t, p.delay(1000), time.Duration(0))
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, Jitter: 0.5}
+
+	delays := make(map[time.Duration]bool)
+	for i := 0; i < 1000; i++ {
+		d := p.delay(1)
+		assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+		assert.LessOrEqual(t, d, 100*time.Millisecond)
+		delays[d] = true
+	}
+
+	assert.Greater(t, len(delays), 1)
+}
+
+func TestRunReportRetriesTransientErrors(t *testing.T) {
+	errTransient := errors.New("temporary failure")
+	tasks := []ContextTask{
+		failingTask(2, errTransient),
+		failingTask(0, errTransient),
+	}
+
+	report, err := RunReport(context.Background(), tasks, Options{Workers: 2, Retry: &RetryPolicy{MaxAttempts: 3}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, report.Results[0].Attempts, 3)
+	assert.Equal(t, report.Results[1].Attempts, 1)
+}
+
+func TestRunReportRetriesExhausted(t *testing.T) {
+	errTransient := errors.New("temporary failure")
+	tasks := []ContextTask{
+		failingTask(3, errTransient),
+		failingTask(0, errTransient),
+	}
+
+	report, err := RunReport(context.Background(), tasks, Options{Workers: 1, Retry: &RetryPolicy{MaxAttempts: 3}})
+
+	assert.ErrorIs(t, err, ErrErrorsLimitExceeded)
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, report.Results[0].Attempts, 3)
+	assert.True(t, report.Results[1].Skipped)
+	assert.Zero(t, report.Results[1].Attempts)
+}
+
+func TestRunReportRetriesOnlyRetryableErrors(t *testing.T) {
+	errTransient := errors.New("temporary failure")
+	errPermanent := errors.New("permanent failure")
+	tasks := []ContextTask{
+		failingTask(1, errTransient),
+		failingTask(1, errPermanent),
+	}
+	retry := &RetryPolicy{
+		MaxAttempts: 3,
+		Retryable: func(err error) bool {
+			return !errors.Is(err, errPermanent)
+		},
+	}
+
+	report, err := RunReport(context.Background(), tasks, Options{Workers: 2, MaxErrors: 1, Retry: retry})
+
+	assert.ErrorIs(t, err, errPermanent)
+	assert.NotErrorIs(t, err, errTransient)
+	assert.NotErrorIs(t, err, ErrErrorsLimitExceeded)
+	assert.Equal(t, report.Results[0].Attempts, 2)
+	assert.Equal(t, report.Results[1].Attempts, 1)
+}
+
+func TestRunContextRetryBacksOff(t *testing.T) {
+	clock := newFakeClock()
+	start := clock.Now()
+	var attempts []time.Duration
+
+	task := func(context.Context) error {
+		attempts = append(attempts, clock.Now().Sub(start))
+		if len(attempts) < 4 {
+			return errors.New("temporary failure")
+		}
+
+		return nil
+	}
+	retry := &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    300 * time.Millisecond,
+		Clock:       clock,
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- RunContext(context.Background(), []ContextTask{task}, Options{Retry: retry})
+	}()
+
+	for _, d := range []time.Duration{100, 200, 300} {
+		clock.waitSleepers(1)
+		clock.Advance(d * time.Millisecond)
+	}
+
+	require.NoError(t, <-done)
+	assert.Equal(t, attempts, []time.Duration{0, 100 * time.Millisecond, 300 * time.Millisecond, 600 * time.Millisecond})
+}
+
+func TestRunReportRetryCancelled(t *testing.T) {
+	clock := newFakeClock()
+	ctx, cancel := context.WithCancel(context.Background())
+	errTransient := errors.New("temporary failure")
+
+	go func() {
+		clock.waitSleepers(1)
+		cancel()
+	}()
+
+	retry := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, Clock: clock}
+	report, err := RunReport(ctx, []ContextTask{failingTask(3, errTransient)}, Options{Retry: retry})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, report.Results[0].Attempts, 1)
+}
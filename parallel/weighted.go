@@ -0,0 +1,103 @@
+package parallel
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// WeightedTask is a task with parameters of its scheduling.
+type WeightedTask struct {
+	Task ContextTask
+	// Priority orders dispatch of tasks, higher goes first. Tasks of equal priority keep their order.
+	Priority int
+	// Weight is share of Options.MaxWeight the task holds while running. Non-positive value means one.
+	// Weight above Options.MaxWeight is capped by it, so such task runs alone.
+	Weight int64
+}
+
+// RunWeighted runs tasks like RunContext, dispatching them in order of priority.
+// A task is started only when total weight of running tasks leaves room for its own,
+// so a heavy task waits for others to finish instead of being passed by lighter ones.
+func RunWeighted(ctx context.Context, tasks []WeightedTask, opts Options) error {
+	r := newRunner(ctx, opts)
+	defer r.cancel()
+
+	r.run(weightedSource(tasks), len(tasks))
+
+	return r.err()
+}
+
+// weightedSource returns jobSource returning tasks in order of priority.
+// Indices of jobs are indices of tasks in the slice.
+func weightedSource(tasks []WeightedTask) jobSource {
+	order := make([]int, len(tasks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return tasks[order[i]].Priority > tasks[order[j]].Priority
+	})
+
+	i := 0
+
+	return func(context.Context) (job, bool) {
+		if i == len(order) {
+			return job{}, false
+		}
+		index := order[i]
+		i++
+
+		return job{index: index, task: tasks[index].Task, weight: max(tasks[index].Weight, 1)}, true
+	}
+}
+
+// semaphore limits total weight of running tasks. Weight is acquired by the feeder only,
+// so a single waiter needs to be woken up.
+type semaphore struct {
+	size int64
+
+	mu       sync.Mutex
+	cur      int64
+	released chan struct{}
+}
+
+func newSemaphore(size int64) *semaphore {
+	return &semaphore{
+		size:     size,
+		released: make(chan struct{}, 1),
+	}
+}
+
+// acquire blocks until given weight is available and takes it.
+// It returns ctx.Err() without taking the weight if ctx is done before that.
+func (s *semaphore) acquire(ctx context.Context, n int64) error {
+	for {
+		s.mu.Lock()
+		if s.cur+n <= s.size {
+			s.cur += n
+			s.mu.Unlock()
+
+			return nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.released:
+		}
+	}
+}
+
+func (s *semaphore) release(n int64) {
+	s.mu.Lock()
+	s.cur -= n
+	s.mu.Unlock()
+
+	// release which happened before the feeder started waiting is remembered
+	select {
+	case s.released <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,119 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// weightTracker records maximum total weight of tasks running at once.
+type weightTracker struct {
+	running atomic.Int64
+	max     atomic.Int64
+}
+
+func (w *weightTracker) task(weight int64) ContextTask {
+	return func(context.Context) error {
+		running := w.running.Add(weight)
+		for {
+			current := w.max.Load()
+			if running <= current || w.max.CompareAndSwap(current, running) {
+				break
+			}
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		w.running.Add(-weight)
+
+		return nil
+	}
+}
+
+func TestRunWeightedDispatchesByPriority(t *testing.T) {
+	var mu sync.Mutex
+	var order []int
+
+	tasks := make([]WeightedTask, 4)
+	for i, priority := range []int{1, 5, 3, 5} {
+		i := i
+		tasks[i] = WeightedTask{
+			Task: func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				order = append(order, i)
+
+				return nil
+			},
+			Priority: priority,
+		}
+	}
+
+	assert.NoError(t, RunWeighted(context.Background(), tasks, Options{Workers: 1}))
+	assert.Equal(t, order, []int{1, 3, 2, 0})
+}
+
+func TestRunWeightedLimitsRunningWeight(t *testing.T) {
+	w := &weightTracker{}
+
+	tasks := make([]WeightedTask, 0, 20)
+	for i := 0; i < 20; i++ {
+		tasks = append(tasks, WeightedTask{Task: w.task(int64(i%4 + 1)), Weight: int64(i%4 + 1)})
+	}
+
+	assert.NoError(t, RunWeighted(context.Background(), tasks, Options{Workers: 10, MaxWeight: 5}))
+	assert.LessOrEqual(t, w.max.Load(), int64(5))
+}
+
+func TestRunWeightedTaskHeavierThanLimit(t *testing.T) {
+	w := &weightTracker{}
+	tasks := []WeightedTask{
+		{Task: w.task(1), Weight: 1},
+		// counted as weight of the limit, so it runs alone
+		{Task: w.task(3), Weight: 10},
+		{Task: w.task(1)},
+	}
+
+	assert.NoError(t, RunWeighted(context.Background(), tasks, Options{Workers: 3, MaxWeight: 3}))
+	assert.LessOrEqual(t, w.max.Load(), int64(3))
+}
+
+func TestRunWeightedCancelledWhileWaitingForWeight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tasks := []WeightedTask{
+		{
+			Task: func(ctx context.Context) error {
+				cancel()
+				<-ctx.Done()
+
+				return nil
+			},
+			Priority: 1,
+			Weight:   2,
+		},
+		{
+			Task: func(context.Context) error {
+				t.Error("task must not start while heavier one is running")
+				return nil
+			},
+			Weight: 1,
+		},
+	}
+
+	assert.ErrorIs(t, RunWeighted(ctx, tasks, Options{Workers: 2, MaxWeight: 2}), context.Canceled)
+}
+
+func TestRunContextMaxWeightLimitsConcurrency(t *testing.T) {
+	w := &weightTracker{}
+	tasks := make([]ContextTask, 10)
+	for i := range tasks {
+		tasks[i] = w.task(1)
+	}
+
+	assert.NoError(t, RunContext(context.Background(), tasks, Options{Workers: 5, MaxWeight: 2}))
+	assert.LessOrEqual(t, w.max.Load(), int64(2))
+}